@@ -0,0 +1,337 @@
+// Package backup stores the content of files before they are overwritten, and restores it.
+//
+// The store is a directory with content-addressed objects, optionally compressed,
+// and an entry of metadata for each backup.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound means the backup is not in the store.
+	ErrNotFound = errors.New("Backup is not found")
+	// ErrCorrupt means the content of backup does not match its checksum.
+	ErrCorrupt = errors.New("Backup is corrupt")
+)
+
+// Entry is the metadata of a backup.
+type Entry struct {
+	ID         string      `json:"id"`
+	Path       string      `json:"path"`
+	Mode       os.FileMode `json:"mode"`
+	Size       int64       `json:"size"`
+	Hash       string      `json:"hash"`
+	Compressed bool        `json:"compressed"`
+	Created    time.Time   `json:"created"`
+}
+
+// Store is the directory of backups.
+type Store struct {
+	mutex    *sync.Mutex
+	dir      string
+	compress bool
+}
+
+// NewStore creates store in dir.
+func NewStore(dir string) *Store {
+	return &Store{
+		mutex: &sync.Mutex{},
+		dir:   dir,
+	}
+}
+
+// SetCompress sets whether new objects are compressed with gzip.
+func (s *Store) SetCompress(compress bool) *Store {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.compress = compress
+	return s
+}
+
+// Dir return the directory of store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save backs up the file at path.
+func (s *Store) Save(path string) (*Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.Put(path, info.Mode(), content)
+}
+
+// Put backs up content of the file at path with mode.
+// The path is recorded as absolute, so that it is restored to the same place
+// after the working directory changes.
+func (s *Store) Put(path string, mode os.FileMode, content []byte) (*Entry, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sum := sha256.Sum256(content)
+	e := &Entry{
+		Path:       path,
+		Mode:       mode,
+		Size:       int64(len(content)),
+		Hash:       hex.EncodeToString(sum[:]),
+		Compressed: s.compress,
+		Created:    time.Now().UTC(),
+	}
+	e.ID = fmt.Sprintf("%d-%s", e.Created.UnixNano(), e.Hash[:12])
+
+	object := s.object(e)
+	if _, err := os.Stat(object); os.IsNotExist(err) {
+		data := content
+		if e.Compressed {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			if _, err := w.Write(content); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			data = buf.Bytes()
+		}
+		if err := writeFile(object, data); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(s.entry(e.ID), b); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Get return the entry of id.
+func (s *Store) Get(id string) (*Entry, error) {
+	b, err := os.ReadFile(s.entry(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Read return the content of backup after checking its integrity.
+func (s *Store) Read(id string) ([]byte, error) {
+	e, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.read(e)
+}
+
+// Verify checks the integrity of backup.
+func (s *Store) Verify(id string) error {
+	_, err := s.Read(id)
+	return err
+}
+
+// Restore writes the content of backup back to its path with its mode after checking its integrity.
+func (s *Store) Restore(id string) error {
+	e, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	content, err := s.read(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return err
+	}
+	tmp := e.Path + ".restore"
+	if err := os.WriteFile(tmp, content, e.Mode.Perm()); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, e.Mode.Perm()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, e.Path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// List return the entries from oldest to newest.
+func (s *Store) List() ([]*Entry, error) {
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, "entries"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		e, err := s.Get(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+	return entries, nil
+}
+
+// Remove removes the backup of id, and its object if no other backup refers to it.
+func (s *Store) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := os.Remove(s.entry(id)); os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", id, ErrNotFound)
+	} else if err != nil {
+		return err
+	}
+	return s.collect()
+}
+
+// Prune removes the backups older than maxAge, and the ones beyond the newest keep.
+// Zero maxAge or keep means no limit. The removed entries are returned.
+func (s *Store) Prune(maxAge time.Duration, keep int) ([]*Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var removed []*Entry
+	for i, e := range entries {
+		newer := len(entries) - 1 - i
+		if (keep > 0 && newer >= keep) || (maxAge > 0 && now.Sub(e.Created) > maxAge) {
+			if err := os.Remove(s.entry(e.ID)); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+			removed = append(removed, e)
+		}
+	}
+	return removed, s.collect()
+}
+
+// collect removes the objects which no entry refers to.
+// It is called with the mutex held, so that no object is put meanwhile.
+func (s *Store) collect() error {
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	used := map[string]bool{}
+	for _, e := range entries {
+		used[filepath.Base(s.object(e))] = true
+	}
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, "objects"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !used[file.Name()] {
+			if err := os.Remove(filepath.Join(s.dir, "objects", file.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) read(e *Entry) ([]byte, error) {
+	data, err := os.ReadFile(s.object(e))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", e.ID, ErrCorrupt)
+	} else if err != nil {
+		return nil, err
+	}
+	if e.Compressed {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.ID, ErrCorrupt)
+		}
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.ID, ErrCorrupt)
+		}
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != e.Hash {
+		return nil, fmt.Errorf("%s: %w", e.ID, ErrCorrupt)
+	}
+	return data, nil
+}
+
+func (s *Store) object(e *Entry) string {
+	name := e.Hash
+	if e.Compressed {
+		name += ".gz"
+	}
+	return filepath.Join(s.dir, "objects", name)
+}
+
+func (s *Store) entry(id string) string {
+	return filepath.Join(s.dir, "entries", id+".json")
+}
+
+// writeFile writes data to path atomically, creating its directory.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
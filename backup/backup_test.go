@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	cwd, _ := os.Getwd()
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+		os.WriteFile(path, []byte("original"), 0600)
+		s := NewStore(filepath.Join(dir, "backup")).SetCompress(compress)
+
+		t.Log("Back up and restore a file.")
+		e, err := s.Save(path)
+		if err != nil {
+			t.Fatalf("Store should be able to save, got %v.", err)
+		}
+		if e.Compressed != compress || e.Size != int64(len("original")) {
+			t.Error("Entry should record the backup.")
+		}
+		os.WriteFile(path, []byte("overwritten"), 0644)
+		if err := s.Restore(e.ID); err != nil {
+			t.Fatalf("Store should be able to restore, got %v.", err)
+		}
+		b, _ := os.ReadFile(path)
+		info, _ := os.Stat(path)
+		if string(b) != "original" || info.Mode().Perm() != 0600 {
+			t.Error("File should be restored with its mode.")
+		}
+
+		t.Log("Record the absolute path of a relative one.")
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			if e, err := s.Save(rel); err != nil || e.Path != path {
+				t.Errorf("Entry should record the absolute path, got %v.", e)
+			}
+		}
+
+		t.Log("Share the object of the same content.")
+		again, _ := s.Save(path)
+		objects, _ := os.ReadDir(filepath.Join(s.Dir(), "objects"))
+		if again.ID == e.ID || len(objects) != 1 {
+			t.Error("Backups of the same content should share the object.")
+		}
+	}
+}
+
+func TestStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir).SetCompress(true)
+	e, err := s.Put(filepath.Join(dir, "config"), 0644, []byte("original"))
+	if err != nil {
+		t.Fatal("Store should be able to put.")
+	}
+	if err := s.Verify(e.ID); err != nil {
+		t.Error("Backup should be intact.")
+	}
+
+	t.Log("Detect a corrupt backup.")
+	os.WriteFile(s.object(e), []byte("corrupt"), 0600)
+	if err := s.Restore(e.ID); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Store should return %v, got %v.", ErrCorrupt, err)
+	}
+	if _, err := os.Stat(e.Path); !os.IsNotExist(err) {
+		t.Error("Corrupt backup should not be restored.")
+	}
+	if err := s.Verify("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Store should return %v, got %v.", ErrNotFound, err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+	var ids []string
+	for _, content := range []string{"a", "b", "c", "d"} {
+		e, _ := s.Put(filepath.Join(dir, "config"), 0644, []byte(content))
+		ids = append(ids, e.ID)
+	}
+
+	t.Log("List backups from oldest to newest.")
+	entries, err := s.List()
+	if err != nil || len(entries) != 4 || entries[0].ID != ids[0] {
+		t.Fatal("Store should list all backups.")
+	}
+
+	t.Log("Prune backups by count.")
+	removed, err := s.Prune(0, 2)
+	if err != nil || len(removed) != 2 || removed[0].ID != ids[0] {
+		t.Errorf("Oldest backups should be pruned, got %v.", removed)
+	}
+	objects, _ := os.ReadDir(filepath.Join(dir, "objects"))
+	if len(objects) != 2 {
+		t.Error("Objects of pruned backups should be removed.")
+	}
+
+	t.Log("Prune backups by age.")
+	time.Sleep(10 * time.Millisecond)
+	if removed, _ := s.Prune(time.Millisecond, 0); len(removed) != 2 {
+		t.Error("Expired backups should be pruned.")
+	}
+
+	t.Log("Remove an unknown backup.")
+	if err := s.Remove(ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Store should return %v, got %v.", ErrNotFound, err)
+	}
+}
+
+func TestStoreConcurrentPrune(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+
+	t.Log("Put backups while pruning.")
+	done := make(chan []string)
+	go func() {
+		var ids []string
+		for i := 0; i < 50; i++ {
+			e, err := s.Put(filepath.Join(dir, "config"), 0644, []byte{byte(i)})
+			if err == nil {
+				ids = append(ids, e.ID)
+			}
+		}
+		done <- ids
+	}()
+	for i := 0; i < 50; i++ {
+		s.Prune(0, 1000)
+	}
+	for _, id := range <-done {
+		if err := s.Verify(id); err != nil {
+			t.Errorf("Backup put while pruning should be intact, got %v.", err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package installer
+
+import (
+	"fmt"
+)
+
+// Builder composes a tree of steppers with named nodes.
+type Builder struct {
+	names    map[string]bool
+	errs     *errorList
+	policy   ErrorPolicy
+	steppers []Stepper
+}
+
+// New creates an empty builder.
+func New() *Builder {
+	return &Builder{
+		names: map[string]bool{},
+		errs:  &errorList{},
+	}
+}
+
+// Step adds a step with doer and undoer.
+func (b *Builder) Step(name string, doer func() error, undoer func() error) *Builder {
+	return b.Add(name, NewStep(doer, undoer))
+}
+
+// Add adds a stepper.
+func (b *Builder) Add(name string, stepper Stepper) *Builder {
+	if !b.name(name) {
+		return b
+	}
+	if stepper == nil {
+		b.fail(name, ErrBuilderNoStepper)
+		return b
+	}
+	for _, err := range validate(stepper, nil) {
+		b.fail(name, err)
+	}
+	b.steppers = append(b.steppers, stepper)
+	return b
+}
+
+// Group adds a steps built by build.
+func (b *Builder) Group(name string, build func(*Builder)) *Builder {
+	if !b.name(name) {
+		return b
+	}
+	child := b.child(build)
+	if len(child.steppers) == 0 {
+		b.fail(name, ErrStepsNoStepper)
+	}
+	b.steppers = append(b.steppers, NewSteps(child.steppers).SetPolicy(child.policy))
+	return b
+}
+
+// Parallel adds a parallel built by build.
+func (b *Builder) Parallel(name string, build func(*Builder)) *Builder {
+	if !b.name(name) {
+		return b
+	}
+	child := b.child(build)
+	if len(child.steppers) == 0 {
+		b.fail(name, ErrParallelNoStepper)
+	}
+	b.steppers = append(b.steppers, NewParallel(child.steppers))
+	return b
+}
+
+// Policy sets the policy of steps being built.
+func (b *Builder) Policy(policy ErrorPolicy) *Builder {
+	b.policy = policy
+	return b
+}
+
+// Build validates the tree and creates the steps.
+func (b *Builder) Build() (*Steps, error) {
+	if len(b.steppers) == 0 {
+		*b.errs = b.errs.append(ErrStepsNoStepper)
+	}
+	if err := b.errs.err(); err != nil {
+		return nil, err
+	}
+	return NewSteps(b.steppers).SetPolicy(b.policy), nil
+}
+
+func (b *Builder) child(build func(*Builder)) *Builder {
+	child := &Builder{
+		names: b.names,
+		errs:  b.errs,
+	}
+	if build != nil {
+		build(child)
+	}
+	return child
+}
+
+// name registers name and reports whether it is valid.
+func (b *Builder) name(name string) bool {
+	if name == "" {
+		*b.errs = b.errs.append(ErrBuilderNoName)
+		return false
+	}
+	if b.names[name] {
+		b.fail(name, ErrBuilderDuplicateName)
+		return false
+	}
+	b.names[name] = true
+	return true
+}
+
+// stepBased implements steppers built on Step.
+type stepBased interface {
+	base() *Step
+}
+
+// validate collects the errors the tree of stepper would fail with when run.
+func validate(stepper Stepper, errs errorList) errorList {
+	switch s := stepper.(type) {
+	case nil:
+		return errs.append(ErrBuilderNoStepper)
+	case *Steps:
+		if len(s.steppers) == 0 {
+			errs = errs.append(ErrStepsNoStepper)
+		}
+		return validateAll(s.steppers, errs)
+	case *Upgrade:
+		return validate(s.Steps, errs)
+	case *Parallel:
+		if len(s.steppers) == 0 {
+			errs = errs.append(ErrParallelNoStepper)
+		}
+		return validateAll(s.steppers, errs)
+	case *Conditional:
+		if s.predicate == nil {
+			errs = errs.append(ErrConditionalNoPredicate)
+		}
+		if s.stepper == nil {
+			return errs.append(ErrConditionalNoStepper)
+		}
+		return validate(s.stepper, errs)
+	case *Optional:
+		return validate(s.Stepper, errs)
+	case *Chaos:
+		return validate(s.Stepper, errs)
+	case *fault:
+		return validate(s.Stepper, errs)
+	case stepBased:
+		if s.base().doer == nil {
+			errs = errs.append(ErrStepNoDoer)
+		}
+	}
+	return errs
+}
+
+func validateAll(steppers []Stepper, errs errorList) errorList {
+	for _, ss := range steppers {
+		errs = validate(ss, errs)
+	}
+	return errs
+}
+
+func (b *Builder) fail(name string, err error) {
+	*b.errs = b.errs.append(fmt.Errorf("%s: %w", name, err))
+}
@@ -0,0 +1,121 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilderBuild(t *testing.T) {
+	t.Log("Build a tree of steppers.")
+	t.Run("Normal", func(t *testing.T) {
+		var calls []string
+		s, err := New().
+			Step("mkdir", func() error {
+				calls = append(calls, "mkdir")
+				return nil
+			}, nil).
+			Group("db", func(b *Builder) {
+				b.Policy(RollbackOnError).
+					Add("schema", recordStep(&calls, "schema", nil, nil)).
+					Add("data", recordStep(&calls, "data", nil, nil))
+			}).
+			Parallel("services", func(b *Builder) {
+				b.Add("web", recordStep(&calls, "web", nil, nil))
+			}).
+			Build()
+		if err != nil {
+			t.Fatal("Builder should be able to build.")
+		}
+		if len(s.steppers) != 3 {
+			t.Fatal("Steps should have all steppers.")
+		}
+		if g, ok := s.steppers[1].(*Steps); !ok || g.Policy() != RollbackOnError || len(g.steppers) != 2 {
+			t.Error("Group should be built as steps.")
+		}
+		if _, ok := s.steppers[2].(*Parallel); !ok {
+			t.Error("Parallel should be built as parallel.")
+		}
+		if err := s.Do(); err != nil || len(calls) != 4 {
+			t.Error("Built steps should be able to do.")
+		}
+	})
+
+	var test = []struct {
+		builder *Builder
+		result  error
+	}{
+		{
+			builder: New(),
+			result:  ErrStepsNoStepper,
+		},
+		{
+			builder: New().Step("a", nil, nil),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", nil),
+			result:  ErrBuilderNoStepper,
+		},
+		{
+			builder: New().Step("", func() error { return nil }, nil),
+			result:  ErrBuilderNoName,
+		},
+		{
+			builder: New().
+				Step("a", func() error { return nil }, nil).
+				Group("b", func(b *Builder) {
+					b.Step("a", func() error { return nil }, nil)
+				}),
+			result: ErrBuilderDuplicateName,
+		},
+		{
+			builder: New().Group("a", func(b *Builder) {}),
+			result:  ErrStepsNoStepper,
+		},
+		{
+			builder: New().Parallel("a", nil),
+			result:  ErrParallelNoStepper,
+		},
+		{
+			builder: New().Add("a", NewOptional(NewStep(nil, nil))),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", NewSteps([]Stepper{NewStep(nil, nil)})),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", NewParallel(nil)),
+			result:  ErrParallelNoStepper,
+		},
+		{
+			builder: New().Add("a", When(func() (bool, string) { return true, "" }, NewStep(nil, nil))),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", When(nil, NewStep(func() error { return nil }, nil))),
+			result:  ErrConditionalNoPredicate,
+		},
+		{
+			builder: New().Add("a", NewTypedStep[int](nil, nil)),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", NewTwoPhase(nil, nil, nil, nil)),
+			result:  ErrStepNoDoer,
+		},
+		{
+			builder: New().Add("a", NewWatchStep(nil)),
+			result:  ErrStepNoDoer,
+		},
+	}
+
+	t.Log("Build an invalid tree of steppers.")
+	for _, tt := range test {
+		t.Run("Invalid", func(t *testing.T) {
+			if s, err := tt.builder.Build(); s != nil || !errors.Is(err, tt.result) {
+				t.Errorf("Builder should return %v, got %v.", tt.result, err)
+			}
+		})
+	}
+}
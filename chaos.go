@@ -0,0 +1,181 @@
+package installer
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Chaos mirrors a tree of steppers, and randomly fails the doer or undoer of its leaves.
+// The faults are reproducible by seed as long as no parallel is in the tree.
+type Chaos struct {
+	Stepper
+
+	mutex *sync.Mutex
+	seed  int64
+	rand  *rand.Rand
+
+	doProbability   float64
+	undoProbability float64
+	doInjected      int
+	undoInjected    int
+}
+
+// NewChaos creates chaos on the tree of stepper without modifying it.
+// Leaves fail before triggering their doer with doProbability, and undoer with undoProbability.
+func NewChaos(stepper Stepper, seed int64, doProbability float64, undoProbability float64) *Chaos {
+	c := &Chaos{
+		mutex:           &sync.Mutex{},
+		seed:            seed,
+		rand:            rand.New(rand.NewSource(seed)),
+		doProbability:   doProbability,
+		undoProbability: undoProbability,
+	}
+	c.Stepper = c.mirror(stepper)
+	return c
+}
+
+// Seed return the seed of chaos.
+func (c *Chaos) Seed() int64 {
+	return c.seed
+}
+
+// DoInjected return the number of failed doers.
+func (c *Chaos) DoInjected() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.doInjected
+}
+
+// UndoInjected return the number of failed undoers.
+func (c *Chaos) UndoInjected() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.undoInjected
+}
+
+// inject decides whether to fail the action.
+func (c *Chaos) inject(action int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	probability := c.doProbability
+	if action < 0 {
+		probability = c.undoProbability
+	}
+	if c.rand.Float64() >= probability {
+		return false
+	}
+	if action > 0 {
+		c.doInjected++
+	} else {
+		c.undoInjected++
+	}
+	return true
+}
+
+func (c *Chaos) mirror(stepper Stepper) Stepper {
+	switch s := stepper.(type) {
+	case *Steps:
+		m := NewSteps(c.mirrorAll(s.steppers))
+		m.policy = s.policy
+		for name, index := range s.savepoints {
+			m.AddSavepoint(name, index)
+		}
+		return m
+	case *Parallel:
+		return NewParallel(c.mirrorAll(s.steppers))
+	case *Conditional:
+		return When(s.predicate, c.mirror(s.stepper))
+	case *Optional:
+		return NewOptional(c.mirror(s.Stepper))
+	}
+	return &fault{
+		Stepper: stepper,
+		chaos:   c,
+	}
+}
+
+func (c *Chaos) mirrorAll(steppers []Stepper) []Stepper {
+	mirrors := make([]Stepper, len(steppers))
+	for i, ss := range steppers {
+		mirrors[i] = c.mirror(ss)
+	}
+	return mirrors
+}
+
+// fault is a leaf stepper which may fail by chaos.
+type fault struct {
+	Stepper
+	chaos  *Chaos
+	action int
+}
+
+func (f *fault) Do() error {
+	return f.execute(1)
+}
+
+func (f *fault) Undo() error {
+	return f.execute(-1)
+}
+
+func (f *fault) execute(action int) error {
+	if f.action != 0 {
+		return ErrStepExecuted
+	}
+	if f.Stepper.Action() == 0 && f.chaos.inject(action) {
+		f.action = action
+		return ErrChaosInjected
+	}
+	return execute(f.Stepper, action)
+}
+
+func (f *fault) Error() error {
+	if f.action != 0 {
+		return ErrChaosInjected
+	}
+	return f.Stepper.Error()
+}
+
+func (f *fault) Action() int {
+	if f.action != 0 {
+		return f.action
+	}
+	return f.Stepper.Action()
+}
+
+func (f *fault) Fin() bool {
+	return f.action != 0 || f.Stepper.Fin()
+}
+
+func (f *fault) Step() int {
+	if f.action != 0 {
+		return 1
+	}
+	return f.Stepper.Step()
+}
+
+func (f *fault) Progress() float64 {
+	if f.action != 0 {
+		return 1
+	}
+	return f.Stepper.Progress()
+}
+
+func (f *fault) Reset() {
+	f.action = 0
+	f.Stepper.Reset()
+}
+
+func (f *fault) Weight() float64 {
+	return weightOf(f.Stepper)
+}
+
+func (f *fault) Interrupt() {
+	interrupt([]Stepper{f.Stepper})
+}
+
+func (c *Chaos) revertPartial() error {
+	if p, ok := c.Stepper.(partial); ok {
+		return p.revertPartial()
+	}
+	return nil
+}
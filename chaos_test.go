@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChaos(t *testing.T) {
+	t.Log("Mirror a tree without fault.")
+	t.Run("Normal", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			NewParallel([]Stepper{recordStep(&calls, "b", nil, nil)}),
+			When(
+				func() (bool, string) { return true, "" },
+				NewOptional(recordStep(&calls, "c", nil, nil)),
+			),
+		}).SetPolicy(RollbackOnError)
+		s.AddSavepoint("save", 1)
+		c := NewChaos(s, 1, 0, 0)
+		if err := c.Do(); err != nil {
+			t.Error("Chaos without fault should be able to do.")
+		}
+		if want := []string{"do a", "do b", "do c"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Chaos should call %v, got %v.", want, calls)
+		}
+		m, ok := c.Stepper.(*Steps)
+		if !ok || m == s || m.policy != s.policy || m.savepoints["save"] != 1 {
+			t.Error("Chaos should mirror steps.")
+		}
+		if s.Action() != 0 {
+			t.Error("Original steps should not be executed.")
+		}
+	})
+
+	t.Log("Fail every doer.")
+	t.Run("Do", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+		})
+		c := NewChaos(s, 1, 1, 0)
+		if err := c.Do(); !errors.Is(err, ErrChaosInjected) {
+			t.Error("Chaos should inject a fault.")
+		}
+		if len(calls) != 0 || c.DoInjected() != 1 || c.UndoInjected() != 0 {
+			t.Error("Doer should not be triggered.")
+		}
+		if err := c.Error(); !errors.Is(err, ErrChaosInjected) {
+			t.Error("Fault should be able to get.")
+		}
+		c.Reset()
+		if c.Action() != 0 {
+			t.Error("Chaos should be cleared.")
+		}
+	})
+
+	t.Log("Fail every undoer.")
+	t.Run("Undo", func(t *testing.T) {
+		var calls []string
+		c := NewChaos(recordStep(&calls, "a", nil, nil), 1, 0, 1)
+		if err := c.Undo(); !errors.Is(err, ErrChaosInjected) {
+			t.Error("Chaos should inject a fault.")
+		}
+		if c.Action() != -1 || !c.Fin() || c.Progress() != 1 || c.Step() != 1 {
+			t.Error("Failed leaf should be finished.")
+		}
+		if err := c.Undo(); err != ErrStepExecuted {
+			t.Error("Failed leaf should not be able to undo.")
+		}
+	})
+
+	t.Log("Reproduce faults by seed.")
+	t.Run("Seed", func(t *testing.T) {
+		run := func() []string {
+			var calls []string
+			s := NewSteps([]Stepper{
+				recordStep(&calls, "a", nil, nil),
+				recordStep(&calls, "b", nil, nil),
+				recordStep(&calls, "c", nil, nil),
+				recordStep(&calls, "d", nil, nil),
+			}).SetPolicy(ContinueOnError)
+			c := NewChaos(s, 42, 0.5, 0.5)
+			c.Do()
+			if c.Seed() != 42 {
+				t.Error("Seed should be the same.")
+			}
+			return calls
+		}
+		if a, b := run(), run(); !reflect.DeepEqual(a, b) {
+			t.Error("Faults should be reproducible.")
+		}
+	})
+}
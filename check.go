@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	kernelPaths   = []string{"/proc/sys/kernel/osrelease"}
+	kernelPattern = regexp.MustCompile(`^(\d+(?:\.\d+)*)`)
+	glibcPaths    = []string{
+		"/lib*/libc.so.6",
+		"/lib*/*/libc.so.6",
+		"/usr/lib*/libc.so.6",
+		"/usr/lib*/*/libc.so.6",
+	}
+	glibcPattern = regexp.MustCompile(`release version (\d+(?:\.\d+)*)`)
+)
+
+// CheckFreeSpace checks the filesystem holding path has at least bytes of free space.
+// The path does not need to exist.
+func CheckFreeSpace(path string, bytes uint64) func() error {
+	return func() error {
+		dir, err := existingDir(path)
+		if err != nil {
+			return err
+		}
+		free, err := freeSpace(dir)
+		if err != nil {
+			return err
+		}
+		if free < bytes {
+			return fmt.Errorf("%s: %w: %d < %d", path, ErrCheckNoSpace, free, bytes)
+		}
+		return nil
+	}
+}
+
+// CheckWritable checks files can be created in dir.
+// If dir does not exist, its nearest existing parent is checked.
+func CheckWritable(dir string) func() error {
+	return func() error {
+		parent, err := existingDir(dir)
+		if err != nil {
+			return err
+		}
+		f, err := ioutil.TempFile(parent, ".installer-check-")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}
+}
+
+// CheckBinary checks the binaries named names are in PATH.
+func CheckBinary(names ...string) func() error {
+	return func() error {
+		var errs errorList
+		for _, name := range names {
+			if _, err := exec.LookPath(name); err != nil {
+				errs = errs.append(err)
+			}
+		}
+		return errs.err()
+	}
+}
+
+// CheckPortFree checks the TCP address, like ":8080", is able to listen.
+func CheckPortFree(address string) func() error {
+	return func() error {
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return fmt.Errorf("%s: %w: %v", address, ErrCheckPortInUse, err)
+		}
+		return l.Close()
+	}
+}
+
+// CheckKernel checks the kernel release is at least min, like "4.18".
+func CheckKernel(min string) func() error {
+	return checkVersion(kernelPaths, kernelPattern, min)
+}
+
+// CheckGlibc checks the version of glibc is at least min, like "2.28".
+func CheckGlibc(min string) func() error {
+	return checkVersion(glibcPaths, glibcPattern, min)
+}
+
+// checkVersion checks the version found by pattern in the first file matched by
+// paths is at least min.
+func checkVersion(paths []string, pattern *regexp.Regexp, min string) func() error {
+	return func() error {
+		for _, path := range paths {
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return err
+			}
+			for _, match := range matches {
+				b, err := ioutil.ReadFile(match)
+				if err != nil {
+					continue
+				}
+				found := pattern.FindSubmatch(b)
+				if found == nil {
+					continue
+				}
+				if version := string(found[1]); compareVersion(version, min) < 0 {
+					return fmt.Errorf("%s: %w: %s < %s", match, ErrCheckOldVersion, version, min)
+				}
+				return nil
+			}
+		}
+		return ErrCheckNoVersion
+	}
+}
+
+// compareVersion compares dotted numeric versions, and the missing parts are zero.
+func compareVersion(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// existingDir return path or its nearest existing parent directory.
+func existingDir(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() {
+				return filepath.Dir(path), nil
+			}
+			return path, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,154 @@
+package installer
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Log("Check free space of a filesystem.")
+	if err := CheckFreeSpace(filepath.Join(dir, "not", "exist"), 1)(); err != nil {
+		t.Error("Free space should be enough.")
+	}
+	if err := CheckFreeSpace(dir, math.MaxUint64)(); !errors.Is(err, ErrCheckNoSpace) {
+		t.Error("Free space should not be enough.")
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Log("Check writability of a directory.")
+	if err := CheckWritable(filepath.Join(dir, "not", "exist"))(); err != nil {
+		t.Error("Directory should be writable.")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Error("Check should clean up.")
+	}
+}
+
+func TestCheckBinary(t *testing.T) {
+	t.Log("Check binaries in PATH.")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckBinary(exe)(); err != nil {
+		t.Error("Binary should be found.")
+	}
+	if err := CheckBinary(exe, "installer-not-exist-a", "installer-not-exist-b")(); err == nil {
+		t.Error("Binary should not be found.")
+	} else if errs, ok := err.(*MultiError); !ok || len(errs.Errors) != 2 {
+		t.Error("All missing binaries should be reported.")
+	}
+}
+
+func TestCheckPortFree(t *testing.T) {
+	t.Log("Check availability of a port.")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	if err := CheckPortFree(address)(); !errors.Is(err, ErrCheckPortInUse) {
+		t.Error("Port should be in use.")
+	}
+	l.Close()
+	if err := CheckPortFree(address)(); err != nil {
+		t.Error("Port should be free.")
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "osrelease"), []byte("5.15.0-91-generic\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "libc.so.6"), []byte("\x00GNU C Library stable release version 2.35.\x00"), 0644)
+
+	var test = []struct {
+		path    string
+		pattern *regexp.Regexp
+		min     string
+		result  error
+	}{
+		{
+			path:    "osrelease",
+			pattern: kernelPattern,
+			min:     "5.4",
+			result:  nil,
+		},
+		{
+			path:    "osrelease",
+			pattern: kernelPattern,
+			min:     "5.15.1",
+			result:  ErrCheckOldVersion,
+		},
+		{
+			path:    "libc.so.*",
+			pattern: glibcPattern,
+			min:     "2.35",
+			result:  nil,
+		},
+		{
+			path:    "libc.so.*",
+			pattern: glibcPattern,
+			min:     "2.100",
+			result:  ErrCheckOldVersion,
+		},
+		{
+			path:    "not-exist",
+			pattern: glibcPattern,
+			min:     "2.28",
+			result:  ErrCheckNoVersion,
+		},
+	}
+
+	t.Log("Check version from local files.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			paths := []string{filepath.Join(dir, tt.path)}
+			if err := checkVersion(paths, tt.pattern, tt.min)(); !errors.Is(err, tt.result) {
+				t.Errorf("Check should return %v, got %v.", tt.result, err)
+			}
+		})
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	t.Log("Compare versions.")
+	var test = []struct {
+		a      string
+		b      string
+		result int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"2", "2.0.1", -1},
+	}
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			if compareVersion(tt.a, tt.b) != tt.result {
+				t.Error("Versions should be compared numerically.")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package installer
+
+// Committer implements a stepper which prepares its work reversibly and commits it at the end.
+// Steps prepares committers in place of doing them, commits all of them once every stepper succeeded,
+// and aborts the prepared ones otherwise.
+type Committer interface {
+	Prepare() error
+	Commit() error
+	Abort() error
+}
+
+// TwoPhase is the step preparing work, then committing or aborting it.
+type TwoPhase struct {
+	*baseStep
+	commit func() error
+	abort  func() error
+}
+
+// NewTwoPhase creates two-phase step with prepare, commit, abort and undoer.
+// Do alone prepares and commits at once, and aborts if commit fails.
+func NewTwoPhase(prepare, commit, abort, undoer func() error) *TwoPhase {
+	return &TwoPhase{
+		baseStep: NewStep(prepare, undoer),
+		commit:   commit,
+		abort:    abort,
+	}
+}
+
+// Do prepares and commits.
+func (t *TwoPhase) Do() error {
+	if err := t.Prepare(); err != nil {
+		return err
+	}
+	if err := t.Commit(); err != nil {
+		return errorList{}.append(err).append(t.Abort()).err()
+	}
+	return nil
+}
+
+// Prepare triggers the preparer.
+func (t *TwoPhase) Prepare() error {
+	return t.baseStep.Do()
+}
+
+// Commit triggers the committer.
+func (t *TwoPhase) Commit() error {
+	return t.finish(t.commit)
+}
+
+// Abort triggers the aborter.
+func (t *TwoPhase) Abort() error {
+	return t.finish(t.abort)
+}
+
+func (t *TwoPhase) finish(f func() error) error {
+	if f == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.step == 0 {
+		return ErrStepNotExecuted
+	}
+	if err := call(f); err != nil {
+		t.err = err
+		return err
+	}
+	return nil
+}
+
+// apply executes stepper for action, and prepares it if it is a committer to do.
+func (s *Steps) apply(i int, action int, prepared []int) ([]int, error) {
+	c, ok := s.steppers[i].(Committer)
+	if !ok || action < 0 {
+		return prepared, execute(s.steppers[i], action)
+	}
+	if err := c.Prepare(); err != nil {
+		return prepared, err
+	}
+	return append(prepared, i), nil
+}
+
+// prepareAborted prepares again the committers before start which are aborted by a previous run.
+func (s *Steps) prepareAborted(start int) ([]int, error) {
+	var prepared []int
+	for i := 0; i < start; i++ {
+		if _, ok := s.steppers[i].(Committer); !ok || s.done[i] {
+			continue
+		}
+		s.steppers[i].Reset()
+		var err error
+		if prepared, err = s.apply(i, 1, prepared); err != nil {
+			return prepared, err
+		}
+		s.done[i] = true
+	}
+	return prepared, nil
+}
+
+// commit commits the prepared committers in order.
+// If one fails, it and the rest are aborted and its index is returned, otherwise -1.
+func (s *Steps) commit(errs errorList, prepared []int) (errorList, int) {
+	for j, i := range prepared {
+		if err := s.steppers[i].(Committer).Commit(); err != nil {
+			return s.abort(errs.append(err), prepared[j:]), i
+		}
+	}
+	return errs, -1
+}
+
+// abort aborts the prepared committers in reverse order, so that they are not undone on rollback.
+func (s *Steps) abort(errs errorList, prepared []int) errorList {
+	for j := len(prepared) - 1; j >= 0; j-- {
+		i := prepared[j]
+		s.done[i] = false
+		errs = errs.append(s.steppers[i].(Committer).Abort())
+	}
+	return errs
+}
@@ -0,0 +1,146 @@
+package installer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func recordTwoPhase(calls *[]string, name string, prepareErr, commitErr error) *TwoPhase {
+	return NewTwoPhase(
+		func() error {
+			*calls = append(*calls, "prepare "+name)
+			return prepareErr
+		},
+		func() error {
+			*calls = append(*calls, "commit "+name)
+			return commitErr
+		},
+		func() error {
+			*calls = append(*calls, "abort "+name)
+			return nil
+		},
+		func() error {
+			*calls = append(*calls, "undo "+name)
+			return nil
+		},
+	)
+}
+
+func TestStepsCommit(t *testing.T) {
+	errDo := errors.New("do")
+	var test = []struct {
+		name     string
+		policy   ErrorPolicy
+		doErr    error
+		prepErr  error
+		commErr  error
+		err      error
+		expected []string
+	}{
+		{"Normal", AbortOnError, nil, nil, nil, nil, []string{
+			"do x", "prepare a", "do y", "prepare b", "commit a", "commit b",
+		}},
+		{"FailDo", AbortOnError, errDo, nil, nil, errDo, []string{
+			"do x", "prepare a", "do y", "abort a",
+		}},
+		{"FailPrepare", RollbackOnError, nil, errDo, nil, errDo, []string{
+			"do x", "prepare a", "do y", "prepare b", "abort a", "undo y", "undo x",
+		}},
+		{"FailCommit", RollbackOnError, nil, nil, errDo, errDo, []string{
+			"do x", "prepare a", "do y", "prepare b", "commit a", "commit b", "abort b", "undo y", "undo a", "undo x",
+		}},
+		{"ContinueOnError", ContinueOnError, errDo, nil, nil, errDo, []string{
+			"do x", "prepare a", "do y", "prepare b", "abort b", "abort a",
+		}},
+	}
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			s := NewSteps([]Stepper{
+				recordStep(&calls, "x", nil, nil),
+				recordTwoPhase(&calls, "a", nil, nil),
+				recordStep(&calls, "y", tt.doErr, nil),
+				recordTwoPhase(&calls, "b", tt.prepErr, tt.commErr),
+			}).SetPolicy(tt.policy)
+			if err := s.Do(); err != tt.err {
+				t.Errorf("Steps should return %v, got %v.", tt.err, err)
+			}
+			if !reflect.DeepEqual(calls, tt.expected) {
+				t.Errorf("Steps should call %v, got %v.", tt.expected, calls)
+			}
+		})
+	}
+}
+
+func TestStepsCommitFailure(t *testing.T) {
+	errDo := errors.New("do")
+	var calls []string
+	s := NewSteps([]Stepper{
+		recordTwoPhase(&calls, "a", nil, nil),
+		recordTwoPhase(&calls, "b", nil, errDo),
+		recordTwoPhase(&calls, "c", nil, nil),
+	})
+
+	t.Log("Abort the committer failed to commit and the rest.")
+	if err := s.Do(); err != errDo {
+		t.Errorf("Steps should return %v, got %v.", errDo, err)
+	}
+	expected := []string{"prepare a", "prepare b", "prepare c", "commit a", "commit b", "abort c", "abort b"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Steps should call %v, got %v.", expected, calls)
+	}
+}
+
+func TestStepsCommitResume(t *testing.T) {
+	errDo := errors.New("do")
+	var calls []string
+	fail := true
+	s := NewSteps([]Stepper{
+		recordTwoPhase(&calls, "a", nil, nil),
+		NewStep(func() error {
+			calls = append(calls, "do y")
+			if fail {
+				return errDo
+			}
+			return nil
+		}, nil),
+	})
+
+	t.Log("Resume with an aborted committer.")
+	if err := s.Do(); err != errDo {
+		t.Fatal("Steps should fail.")
+	}
+	fail = false
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Steps should be able to resume, got %v.", err)
+	}
+	expected := []string{"prepare a", "do y", "abort a", "prepare a", "do y", "commit a"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Aborted committer should be prepared again as %v, got %v.", expected, calls)
+	}
+}
+
+func TestTwoPhase(t *testing.T) {
+	errDo := errors.New("do")
+	var calls []string
+
+	t.Log("Do a two-phase step alone.")
+	if err := recordTwoPhase(&calls, "a", nil, nil).Do(); err != nil {
+		t.Error("TwoPhase should be able to do.")
+	}
+	t.Log("Fail to commit a two-phase step alone.")
+	b := recordTwoPhase(&calls, "b", nil, errDo)
+	if err := b.Do(); err != errDo || b.Error() != errDo {
+		t.Errorf("TwoPhase should return %v, got %v.", errDo, err)
+	}
+	expected := []string{"prepare a", "commit a", "prepare b", "commit b", "abort b"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("TwoPhase should call %v, got %v.", expected, calls)
+	}
+
+	t.Log("Commit a not prepared two-phase step.")
+	if err := recordTwoPhase(&calls, "c", nil, nil).Commit(); err != ErrStepNotExecuted {
+		t.Errorf("TwoPhase should return %v, got %v.", ErrStepNotExecuted, err)
+	}
+}
@@ -0,0 +1,249 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/silver886/installer/version"
+)
+
+// Component is an installable part with dependencies and conflicts.
+type Component struct {
+	mutex     *sync.Mutex
+	name      string
+	version   string
+	requires  map[string]string
+	conflicts []string
+	stepper   Stepper
+}
+
+// NewComponent creates component of name and version installed by stepper.
+func NewComponent(name, version string, stepper Stepper) *Component {
+	return &Component{
+		mutex:    &sync.Mutex{},
+		name:     name,
+		version:  version,
+		requires: map[string]string{},
+		stepper:  stepper,
+	}
+}
+
+// Require adds a dependency on component of name with version constraint.
+// An empty constraint accepts any version.
+func (c *Component) Require(name, constraint string) *Component {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.requires[name] = constraint
+	return c
+}
+
+// Conflict adds components which cannot be installed with component.
+func (c *Component) Conflict(names ...string) *Component {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.conflicts = append(c.conflicts, names...)
+	return c
+}
+
+// Name return the name of component.
+func (c *Component) Name() string {
+	return c.name
+}
+
+// Version return the version of component.
+func (c *Component) Version() string {
+	return c.version
+}
+
+// Stepper return the stepper of component.
+func (c *Component) Stepper() Stepper {
+	return c.stepper
+}
+
+// dependencies return the names of required components in order.
+func (c *Component) dependencies() []string {
+	names := make([]string, 0, len(c.requires))
+	for name := range c.requires {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Catalog is the set of components to select from.
+type Catalog struct {
+	components map[string]*Component
+}
+
+// NewCatalog creates catalog of components, and validates their versions and requirements.
+func NewCatalog(components ...*Component) (*Catalog, error) {
+	c := &Catalog{
+		components: map[string]*Component{},
+	}
+	var errs errorList
+	for _, component := range components {
+		switch {
+		case component == nil || component.name == "":
+			errs = errs.append(ErrComponentNoName)
+			continue
+		case component.stepper == nil:
+			errs = errs.append(fmt.Errorf("%s: %w", component.name, ErrComponentNoStepper))
+		case c.components[component.name] != nil:
+			errs = errs.append(fmt.Errorf("%s: %w", component.name, ErrComponentDuplicate))
+		}
+		if component.version != "" {
+			if _, err := version.Parse(component.version); err != nil {
+				errs = errs.append(fmt.Errorf("%s: %w", component.name, err))
+			}
+		}
+		for _, name := range component.dependencies() {
+			if _, err := version.ParseConstraint(component.requires[name]); err != nil {
+				errs = errs.append(fmt.Errorf("%s: %w", component.name, err))
+			}
+		}
+		c.components[component.name] = component
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Component return the component of name, or nil if not in catalog.
+func (c *Catalog) Component(name string) *Component {
+	return c.components[name]
+}
+
+// Resolve return the selected components with their dependencies,
+// ordered so that each component comes after its dependencies.
+func (c *Catalog) Resolve(selected ...string) ([]*Component, error) {
+	var order []*Component
+	states := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch states[name] {
+		case 1:
+			return fmt.Errorf("%s: %w", name, ErrComponentCycle)
+		case 2:
+			return nil
+		}
+		component := c.components[name]
+		if component == nil {
+			return fmt.Errorf("%s: %w", name, ErrComponentUnknown)
+		}
+		states[name] = 1
+		for _, dependency := range component.dependencies() {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+			if err := c.satisfy(component, dependency); err != nil {
+				return err
+			}
+		}
+		states[name] = 2
+		order = append(order, component)
+		return nil
+	}
+	for _, name := range selected {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, component := range order {
+		for _, name := range component.conflicts {
+			if states[name] == 2 {
+				return nil, fmt.Errorf("%s: %s: %w", component.name, name, ErrComponentConflict)
+			}
+		}
+	}
+	return order, nil
+}
+
+// Install creates steps installing the selected components with their dependencies.
+func (c *Catalog) Install(selected ...string) (*Steps, error) {
+	components, err := c.Resolve(selected...)
+	if err != nil {
+		return nil, err
+	}
+	steppers := make([]Stepper, len(components))
+	for i, component := range components {
+		steppers[i] = component.stepper
+	}
+	return NewSteps(steppers), nil
+}
+
+// Uninstall creates steps removing components from the installed ones.
+// It refuses if any remaining installed component requires them.
+func (c *Catalog) Uninstall(installed []string, names ...string) (*Steps, error) {
+	return c.uninstall(installed, names, false)
+}
+
+// UninstallCascade creates steps removing components from the installed ones,
+// together with the installed components requiring them.
+func (c *Catalog) UninstallCascade(installed []string, names ...string) (*Steps, error) {
+	return c.uninstall(installed, names, true)
+}
+
+func (c *Catalog) uninstall(installed, names []string, cascade bool) (*Steps, error) {
+	order, err := c.Resolve(installed...)
+	if err != nil {
+		return nil, err
+	}
+	removed := map[string]bool{}
+	for _, name := range names {
+		if c.components[name] == nil {
+			return nil, fmt.Errorf("%s: %w", name, ErrComponentUnknown)
+		}
+		removed[name] = true
+	}
+
+	// Dependents come after their dependencies, so one pass in order covers the closure.
+	var errs errorList
+	for _, component := range order {
+		if removed[component.name] {
+			continue
+		}
+		for _, dependency := range component.dependencies() {
+			if !removed[dependency] {
+				continue
+			}
+			if cascade {
+				removed[component.name] = true
+				break
+			}
+			errs = errs.append(fmt.Errorf("%s: %s: %w", dependency, component.name, ErrComponentRequired))
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	var steppers []Stepper
+	for i := len(order) - 1; i >= 0; i-- {
+		if removed[order[i].name] {
+			steppers = append(steppers, &inverse{
+				Stepper: order[i].stepper,
+			})
+		}
+	}
+	return NewSteps(steppers), nil
+}
+
+// satisfy checks the version of dependency against the requirement of component.
+func (c *Catalog) satisfy(component *Component, dependency string) error {
+	constraint := component.requires[dependency]
+	if constraint == "" {
+		return nil
+	}
+	parsed, err := version.ParseConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("%s: %w", component.name, err)
+	}
+	v, err := version.Parse(c.components[dependency].version)
+	if err != nil || !parsed.Check(v) {
+		return fmt.Errorf("%s: %s %s: %w", component.name, dependency, constraint, ErrComponentVersion)
+	}
+	return nil
+}
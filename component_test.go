@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/silver886/installer/version"
+)
+
+func catalog(t *testing.T, calls *[]string) *Catalog {
+	c, err := NewCatalog(
+		NewComponent("core", "1.4.0", recordStep(calls, "core", nil, nil)),
+		NewComponent("cli", "1.0.0", recordStep(calls, "cli", nil, nil)).
+			Require("core", ">=1.2 <2"),
+		NewComponent("docs", "1.0.0", recordStep(calls, "docs", nil, nil)),
+		NewComponent("plugin", "0.1.0", recordStep(calls, "plugin", nil, nil)).
+			Require("cli", "^1").
+			Require("core", ""),
+		NewComponent("legacy", "0.9.0", recordStep(calls, "legacy", nil, nil)).
+			Conflict("plugin"),
+	)
+	if err != nil {
+		t.Fatalf("Catalog should be able to create, got %v.", err)
+	}
+	return c
+}
+
+func TestCatalogInstall(t *testing.T) {
+	var calls []string
+	c := catalog(t, &calls)
+
+	t.Log("Install selected components with dependencies.")
+	s, err := c.Install("docs", "plugin")
+	if err != nil {
+		t.Fatal("Components should be able to resolve.")
+	}
+	if err := s.Do(); err != nil {
+		t.Fatal("Steps should be able to do.")
+	}
+	expected := []string{"do docs", "do core", "do cli", "do plugin"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Components should be installed as %v, got %v.", expected, calls)
+	}
+}
+
+func TestCatalogResolveError(t *testing.T) {
+	var calls []string
+	c := catalog(t, &calls)
+	old, _ := NewCatalog(
+		NewComponent("core", "2.0.0", recordStep(&calls, "core", nil, nil)),
+		NewComponent("cli", "1.0.0", recordStep(&calls, "cli", nil, nil)).
+			Require("core", "<2"),
+	)
+	cyclic, _ := NewCatalog(
+		NewComponent("a", "", recordStep(&calls, "a", nil, nil)).Require("b", ""),
+		NewComponent("b", "", recordStep(&calls, "b", nil, nil)).Require("a", ""),
+	)
+	var test = []struct {
+		catalog  *Catalog
+		selected []string
+		err      error
+	}{
+		{c, []string{"unknown"}, ErrComponentUnknown},
+		{c, []string{"plugin", "legacy"}, ErrComponentConflict},
+		{old, []string{"cli"}, ErrComponentVersion},
+		{cyclic, []string{"a"}, ErrComponentCycle},
+	}
+	for _, tt := range test {
+		if _, err := tt.catalog.Resolve(tt.selected...); !errors.Is(err, tt.err) {
+			t.Errorf("Resolve %v should return %v, got %v.", tt.selected, tt.err, err)
+		}
+	}
+
+	t.Log("Resolve a requirement changed after creating the catalog.")
+	c.Component("cli").Require("core", ">>1")
+	if _, err := c.Resolve("cli"); !errors.Is(err, version.ErrInvalidConstraint) {
+		t.Errorf("Resolve should return %v, got %v.", version.ErrInvalidConstraint, err)
+	}
+
+	t.Log("Create an invalid catalog.")
+	_, err := NewCatalog(
+		NewComponent("", "", nil),
+		NewComponent("a", "bad", nil).Require("b", ">>1"),
+		NewComponent("b", "", recordStep(&calls, "b", nil, nil)),
+		NewComponent("b", "", recordStep(&calls, "b", nil, nil)),
+	)
+	errs, ok := err.(*MultiError)
+	if !ok || len(errs.Errors) != 5 {
+		t.Errorf("Each invalid component should be reported, got %v.", err)
+	}
+}
+
+func TestCatalogUninstall(t *testing.T) {
+	installed := []string{"docs", "plugin"}
+
+	t.Log("Refuse to uninstall a required component.")
+	var calls []string
+	c := catalog(t, &calls)
+	if _, err := c.Uninstall(installed, "cli"); !errors.Is(err, ErrComponentRequired) {
+		t.Errorf("Uninstall should return %v, got %v.", ErrComponentRequired, err)
+	}
+
+	t.Log("Uninstall a component which is not required.")
+	s, err := c.Uninstall(installed, "plugin")
+	if err != nil || s.Do() != nil {
+		t.Fatal("Component should be able to uninstall.")
+	}
+	if !reflect.DeepEqual(calls, []string{"undo plugin"}) {
+		t.Errorf("Only the component should be uninstalled, got %v.", calls)
+	}
+
+	t.Log("Uninstall a required component with its dependents.")
+	calls = nil
+	c = catalog(t, &calls)
+	s, err = c.UninstallCascade(installed, "core")
+	if err != nil || s.Do() != nil {
+		t.Fatal("Component should be able to uninstall.")
+	}
+	expected := []string{"undo plugin", "undo cli", "undo core"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Dependents should be uninstalled first as %v, got %v.", expected, calls)
+	}
+}
@@ -0,0 +1,180 @@
+package installer
+
+import (
+	"math"
+	"sync"
+)
+
+// Predicate decides whether a conditional stepper should run,
+// and gives the reason of the decision.
+type Predicate func() (bool, string)
+
+// Conditional is a stepper which only runs when its predicate holds.
+type Conditional struct {
+	mutex   *sync.Mutex
+	step    int
+	skipped bool
+	reason  string
+
+	// decided, held and cause keep the decision of the last Do through Reset,
+	// so that the stepper is undone as it was done.
+	decided bool
+	held    bool
+	cause   string
+
+	predicate Predicate
+	stepper   Stepper
+}
+
+// When creates conditional stepper with predicate and stepper.
+func When(predicate Predicate, stepper Stepper) *Conditional {
+	return &Conditional{
+		mutex:     &sync.Mutex{},
+		predicate: predicate,
+		stepper:   stepper,
+	}
+}
+
+// Do triggers the stepper's doer if the predicate holds.
+func (c *Conditional) Do() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.check(); err != nil {
+		return err
+	}
+	if c.step != 0 {
+		return ErrConditionalExecuted
+	}
+	c.step++
+	if !c.decide() {
+		return nil
+	}
+	return c.stepper.Do()
+}
+
+// Undo triggers the stepper's undoer if the predicate held on last Do,
+// or if the predicate holds without an earlier Do.
+func (c *Conditional) Undo() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.check(); err != nil {
+		return err
+	}
+	if c.step != 0 {
+		return ErrConditionalExecuted
+	}
+	c.step--
+	ok := c.held
+	if c.decided {
+		c.skipped, c.reason = !ok, c.cause
+	} else {
+		ok = c.evaluate()
+	}
+	if !ok {
+		c.decided = false
+		return nil
+	}
+	err := c.stepper.Undo()
+	if err == nil {
+		c.decided = false
+	}
+	return err
+}
+
+// Error return the error during executing stepper.
+func (c *Conditional) Error() error {
+	if c.step == 0 {
+		return ErrConditionalNotExecuted
+	}
+	if c.skipped {
+		return nil
+	}
+	return c.stepper.Error()
+}
+
+// Action return current action of conditional.
+func (c *Conditional) Action() int {
+	if c.step > 0 {
+		return 1
+	} else if c.step < 0 {
+		return -1
+	}
+	return 0
+}
+
+// Fin return the status of conditional.
+func (c *Conditional) Fin() bool {
+	if c.skipped {
+		return true
+	}
+	return c.stepper.Fin()
+}
+
+// Step return the step status of conditional.
+func (c *Conditional) Step() int {
+	if c.skipped {
+		return int(math.Abs(float64(c.step)))
+	}
+	return c.stepper.Step()
+}
+
+// Progress return the progress status of conditional.
+func (c *Conditional) Progress() float64 {
+	if c.skipped {
+		return math.Abs(float64(c.step))
+	}
+	return c.stepper.Progress()
+}
+
+// Reset clears the status, but keeps the decision of the last Do for undoing.
+func (c *Conditional) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stepper != nil {
+		c.stepper.Reset()
+	}
+	c.step = 0
+	c.skipped = false
+	c.reason = ""
+}
+
+func (c *Conditional) revertPartial() error {
+	if p, ok := c.stepper.(partial); ok && !c.skipped {
+		return p.revertPartial()
+	}
+	return nil
+}
+
+// Skipped return whether the stepper is skipped by the predicate.
+func (c *Conditional) Skipped() bool {
+	return c.skipped
+}
+
+// Reason return the reason given by the predicate on last evaluation.
+func (c *Conditional) Reason() string {
+	return c.reason
+}
+
+// decide evaluates the predicate and keeps the decision for undoing.
+func (c *Conditional) decide() bool {
+	c.decided, c.held = true, c.evaluate()
+	c.cause = c.reason
+	return c.held
+}
+
+func (c *Conditional) evaluate() bool {
+	ok, reason := c.predicate()
+	c.skipped = !ok
+	c.reason = reason
+	return ok
+}
+
+func (c *Conditional) check() error {
+	if c.predicate == nil {
+		return ErrConditionalNoPredicate
+	}
+	if c.stepper == nil {
+		return ErrConditionalNoStepper
+	}
+	return nil
+}
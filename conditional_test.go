@@ -0,0 +1,254 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConditionalNew(t *testing.T) {
+	t.Log("Create a conditional.")
+	var normalTest = []struct {
+		predicate Predicate
+		stepper   Stepper
+	}{
+		{
+			func() (bool, string) { return true, "" },
+			NewStep(
+				func() error { return nil },
+				func() error { return nil },
+			),
+		},
+		{
+			func() (bool, string) { return false, "" },
+			NewStep(
+				func() error { return nil },
+				func() error { return nil },
+			),
+		},
+		{
+			nil,
+			nil,
+		},
+	}
+	for _, tt := range normalTest {
+		t.Run("Normal", func(t *testing.T) {
+			if c := When(tt.predicate, tt.stepper); c == nil {
+				t.Error("Conditional should be able to create.")
+			}
+		})
+	}
+}
+
+func TestConditionalDo(t *testing.T) {
+	var test = []struct {
+		ok      bool
+		doer    func() error
+		result  error
+		skipped bool
+	}{
+		{
+			ok:      true,
+			doer:    func() error { return nil },
+			result:  nil,
+			skipped: false,
+		},
+		{
+			ok:      true,
+			doer:    func() error { return errors.New("") },
+			result:  errors.New(""),
+			skipped: false,
+		},
+		{
+			ok:      false,
+			doer:    func() error { return errors.New("") },
+			result:  nil,
+			skipped: true,
+		},
+	}
+
+	t.Log("Normally do a conditional.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			ok := tt.ok
+			c := &Conditional{
+				mutex:     &sync.Mutex{},
+				predicate: func() (bool, string) { return ok, "reason" },
+				stepper:   NewStep(tt.doer, nil),
+			}
+			if err := c.Do(); err != tt.result && err.Error() != tt.result.Error() {
+				t.Error("Conditional should be able to do.")
+			}
+			if c.Skipped() != tt.skipped {
+				t.Error("Conditional should record whether it is skipped.")
+			}
+			if c.Reason() != "reason" {
+				t.Error("Conditional should record the reason of predicate.")
+			}
+			if !c.Fin() || c.Action() != 1 {
+				t.Error("Conditional should be done.")
+			}
+		})
+	}
+
+	t.Log("Do an emtpy conditional.")
+	t.Run("Emtpy", func(t *testing.T) {
+		c := &Conditional{
+			mutex: &sync.Mutex{},
+		}
+		if err := c.Do(); err != ErrConditionalNoPredicate {
+			t.Error("Conditional should not be able to do.")
+		}
+		c.predicate = func() (bool, string) { return true, "" }
+		if err := c.Do(); err != ErrConditionalNoStepper {
+			t.Error("Conditional should not be able to do.")
+		}
+	})
+
+	t.Log("Do an executed conditional.")
+	for _, tt := range test {
+		t.Run("Executed", func(t *testing.T) {
+			ok := tt.ok
+			c := &Conditional{
+				mutex:     &sync.Mutex{},
+				predicate: func() (bool, string) { return ok, "" },
+				stepper:   NewStep(tt.doer, nil),
+				step:      1,
+			}
+			if err := c.Do(); err != ErrConditionalExecuted {
+				t.Error("Conditional should not be able to do.")
+			}
+		})
+	}
+}
+
+func TestConditionalUndo(t *testing.T) {
+	var test = []struct {
+		ok      bool
+		undoer  func() error
+		result  error
+		skipped bool
+	}{
+		{
+			ok:      true,
+			undoer:  func() error { return nil },
+			result:  nil,
+			skipped: false,
+		},
+		{
+			ok:      true,
+			undoer:  func() error { return errors.New("") },
+			result:  errors.New(""),
+			skipped: false,
+		},
+		{
+			ok:      false,
+			undoer:  func() error { return errors.New("") },
+			result:  nil,
+			skipped: true,
+		},
+	}
+
+	t.Log("Normally undo a conditional.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			ok := tt.ok
+			c := &Conditional{
+				mutex:     &sync.Mutex{},
+				predicate: func() (bool, string) { return ok, "" },
+				stepper:   NewStep(nil, tt.undoer),
+			}
+			if err := c.Undo(); err != tt.result && err.Error() != tt.result.Error() {
+				t.Error("Conditional should be able to undo.")
+			}
+			if c.Skipped() != tt.skipped {
+				t.Error("Conditional should record whether it is skipped.")
+			}
+			if !c.Fin() || c.Action() != -1 {
+				t.Error("Conditional should be undone.")
+			}
+		})
+	}
+}
+
+func TestConditionalReset(t *testing.T) {
+	t.Log("Redo a conditional.")
+	ok := false
+	c := &Conditional{
+		mutex:     &sync.Mutex{},
+		predicate: func() (bool, string) { return ok, "" },
+		stepper: NewStep(
+			func() error { return errors.New("") },
+			nil,
+		),
+	}
+	c.Do()
+	c.Reset()
+	if c.Skipped() || c.Reason() != "" || c.Action() != 0 {
+		t.Error("Conditional should be cleared.")
+	}
+	ok = true
+	if err := c.Do(); err == nil {
+		t.Error("Conditional should be able to redo.")
+	}
+}
+
+func TestConditionalError(t *testing.T) {
+	t.Log("Get error from a non-executed conditional.")
+	c := When(
+		func() (bool, string) { return false, "" },
+		NewStep(
+			func() error { return errors.New("") },
+			nil,
+		),
+	)
+	if err := c.Error(); err != ErrConditionalNotExecuted {
+		t.Error("Error should not be able to get.")
+	}
+
+	t.Log("Get error from a skipped conditional.")
+	c.Do()
+	if err := c.Error(); err != nil {
+		t.Error("Skipped conditional should not have error.")
+	}
+	if c.Progress() != 1 || c.Step() != 1 {
+		t.Error("Skipped conditional should be complete.")
+	}
+}
+
+func TestConditionalRollback(t *testing.T) {
+	errDo := errors.New("do")
+	path := filepath.Join(t.TempDir(), "file")
+	missing := func() (bool, string) {
+		_, err := os.Stat(path)
+		return os.IsNotExist(err), path
+	}
+
+	t.Log("Roll back a conditional whose doer changed the predicate.")
+	s := NewSteps([]Stepper{
+		When(missing, NewFileStep(path, []byte("content"), 0644)),
+		NewStep(func() error { return errDo }, nil),
+	}).SetPolicy(RollbackOnError)
+	if err := s.Do(); !errors.Is(err, errDo) {
+		t.Fatalf("Steps should fail with %v, got %v.", errDo, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("File created by the conditional should be removed.")
+	}
+
+	t.Log("Roll back a skipped conditional whose predicate turned to hold.")
+	var calls []string
+	ok := false
+	s = NewSteps([]Stepper{
+		When(func() (bool, string) { return ok, "" }, recordStep(&calls, "a", nil, nil)),
+		NewStep(func() error { ok = true; return errDo }, nil),
+	}).SetPolicy(RollbackOnError)
+	if err := s.Do(); !errors.Is(err, errDo) {
+		t.Fatalf("Steps should fail with %v, got %v.", errDo, err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("Skipped conditional should not be undone, got %v.", calls)
+	}
+}
@@ -1,6 +1,10 @@
 package installer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 
@@ -10,6 +14,14 @@ var (
 	ErrStepsExecuted = errors.New("Steps is already executed")
 	// ErrStepsNotExecuted means the steps is not executed.
 	ErrStepsNotExecuted = errors.New("Steps is not executed")
+	// ErrStepsNoSavepoint means the steps does not have the savepoint.
+	ErrStepsNoSavepoint = errors.New("Steps has no such savepoint")
+	// ErrStepsSavepointRange means the savepoint is out of the steppers.
+	ErrStepsSavepointRange = errors.New("Steps savepoint is out of range")
+	// ErrStepsSavepointNotReached means the steps has not reached the savepoint.
+	ErrStepsSavepointNotReached = errors.New("Steps has not reached the savepoint")
+	// ErrStepsInterrupted means the steps is interrupted.
+	ErrStepsInterrupted = errors.New("Steps is interrupted")
 
 	// ErrStepNoDoer means the step does not have doer.
 	ErrStepNoDoer = errors.New("Step has no doer")
@@ -19,4 +31,174 @@ var (
 	ErrStepExecuted = errors.New("Step is already executed")
 	// ErrStepNotExecuted means the step is not executed.
 	ErrStepNotExecuted = errors.New("Step is not executed")
+	// ErrStepNoValue means the step does not have value for undoer.
+	ErrStepNoValue = errors.New("Step has no value")
+
+	// ErrConditionalNoPredicate means the conditional does not have predicate.
+	ErrConditionalNoPredicate = errors.New("Conditional has no predicate")
+	// ErrConditionalNoStepper means the conditional does not have stepper.
+	ErrConditionalNoStepper = errors.New("Conditional has no stepper")
+	// ErrConditionalExecuted means the conditional is already executed.
+	ErrConditionalExecuted = errors.New("Conditional is already executed")
+	// ErrConditionalNotExecuted means the conditional is not executed.
+	ErrConditionalNotExecuted = errors.New("Conditional is not executed")
+
+	// ErrParallelNoStepper means the parallel does not have any stepper.
+	ErrParallelNoStepper = errors.New("Parallel has no stepper")
+	// ErrParallelExecuted means the parallel is already executed.
+	ErrParallelExecuted = errors.New("Parallel is already executed")
+	// ErrParallelNotExecuted means the parallel is not executed.
+	ErrParallelNotExecuted = errors.New("Parallel is not executed")
+
+	// ErrBuilderNoName means the builder is given an empty name.
+	ErrBuilderNoName = errors.New("Builder is given an empty name")
+	// ErrBuilderDuplicateName means the builder is given a name already used.
+	ErrBuilderDuplicateName = errors.New("Builder is given a duplicate name")
+	// ErrBuilderNoStepper means the builder is given a nil stepper.
+	ErrBuilderNoStepper = errors.New("Builder is given a nil stepper")
+
+	// ErrCheckNoSpace means the check finds not enough free space.
+	ErrCheckNoSpace = errors.New("Check has not enough free space")
+	// ErrCheckPortInUse means the check finds the port in use.
+	ErrCheckPortInUse = errors.New("Check has port in use")
+	// ErrCheckOldVersion means the check finds a version older than required.
+	ErrCheckOldVersion = errors.New("Check has version older than required")
+	// ErrCheckNoVersion means the check finds no version.
+	ErrCheckNoVersion = errors.New("Check has no version found")
+	// ErrCheckUnsupported means the check is not supported on the platform.
+	ErrCheckUnsupported = errors.New("Check is not supported on the platform")
+
+	// ErrVerifyMissing means the verification finds the path missing.
+	ErrVerifyMissing = errors.New("Verify has path missing")
+	// ErrVerifyChanged means the verification finds the content changed.
+	ErrVerifyChanged = errors.New("Verify has content changed")
+	// ErrVerifyRetargeted means the verification finds the symbolic link retargeted.
+	ErrVerifyRetargeted = errors.New("Verify has symbolic link retargeted")
+	// ErrVerifyType means the verification finds the path of another type.
+	ErrVerifyType = errors.New("Verify has path of another type")
+
+	// ErrUpgradeNoRelease means the upgrade does not have the next release.
+	ErrUpgradeNoRelease = errors.New("Upgrade has no release")
+	// ErrUpgradeNoMigration means the upgrade does not have migrations from the installed version.
+	ErrUpgradeNoMigration = errors.New("Upgrade has no migration")
+	// ErrUpgradeNoStepper means the upgrade does not have the stepper to remove or replace an installed step.
+	ErrUpgradeNoStepper = errors.New("Upgrade has no stepper for installed step")
+
+	// ErrComponentNoName means the component does not have name.
+	ErrComponentNoName = errors.New("Component has no name")
+	// ErrComponentNoStepper means the component does not have stepper.
+	ErrComponentNoStepper = errors.New("Component has no stepper")
+	// ErrComponentDuplicate means the component name is already used.
+	ErrComponentDuplicate = errors.New("Component has a duplicate name")
+	// ErrComponentUnknown means the component is not in the catalog.
+	ErrComponentUnknown = errors.New("Component is unknown")
+	// ErrComponentVersion means the component version does not satisfy the requirement.
+	ErrComponentVersion = errors.New("Component version does not satisfy requirement")
+	// ErrComponentConflict means the component conflicts with another selected one.
+	ErrComponentConflict = errors.New("Component conflicts with another")
+	// ErrComponentCycle means the component requires itself through its dependencies.
+	ErrComponentCycle = errors.New("Component has cyclic dependency")
+	// ErrComponentRequired means the component is required by other installed ones.
+	ErrComponentRequired = errors.New("Component is required by others")
+
+	// ErrFileNoBackup means the file step does not have the record of backup to undo.
+	ErrFileNoBackup = errors.New("File has no backup record")
+
+	// ErrWatchNoSnapshot means the watch does not have the snapshot before doing.
+	ErrWatchNoSnapshot = errors.New("Watch has no snapshot")
+
+	// ErrTrackerNotFound means the tracker of step is neither created nor restored.
+	ErrTrackerNotFound = errors.New("Tracker is not found")
+	// ErrTrackerNoCleanup means the tracker does not have cleanup for a custom resource.
+	ErrTrackerNoCleanup = errors.New("Tracker has no cleanup")
+	// ErrTrackerProcessUnknown means the tracker does not have the handle of a process restored from JSON.
+	ErrTrackerProcessUnknown = errors.New("Tracker has unknown process")
+
+	// ErrHealthStatus means the health check gets an unhealthy status.
+	ErrHealthStatus = errors.New("Health check has unhealthy status")
+
+	// ErrChaosInjected means the chaos injected a fault.
+	ErrChaosInjected = errors.New("Chaos injected a fault")
 )
+
+// PanicError is the error recovered from a panic during executing a step.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error return the message with the panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Step panicked: %v", e.Value)
+}
+
+// Unwrap return the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// MultiError is the set of errors collected during executing steppers.
+type MultiError struct {
+	Errors []error
+}
+
+// Error joins the messages of all errors.
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap return all errors.
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// Is reports whether any error matches target.
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error that matches target.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorList collects errors and flattens MultiError.
+type errorList []error
+
+func (l errorList) append(err error) errorList {
+	if err == nil {
+		return l
+	}
+	if m, ok := err.(*MultiError); ok {
+		return append(l, m.Errors...)
+	}
+	return append(l, err)
+}
+
+// err return nil for no error, the error itself for single one, otherwise MultiError.
+func (l errorList) err() error {
+	switch len(l) {
+	case 0:
+		return nil
+	case 1:
+		return l[0]
+	}
+	return &MultiError{
+		Errors: l,
+	}
+}
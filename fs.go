@@ -0,0 +1,309 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/silver886/installer/backup"
+)
+
+// FileStep is the step writing a file.
+type FileStep struct {
+	*baseStep
+	path     string
+	content  []byte
+	perm     os.FileMode
+	checksum [sha256.Size]byte
+	backup   *backup.Store
+	existed  bool
+	saved    string
+	recorded bool
+}
+
+// NewFileStep creates step writing content to the file at path with perm.
+// Undo removes the file, or restores the overwritten one if backed up.
+// A file overwritten without backup is kept.
+func NewFileStep(path string, content []byte, perm os.FileMode) *FileStep {
+	f := &FileStep{
+		path:     path,
+		content:  content,
+		perm:     perm,
+		checksum: sha256.Sum256(content),
+	}
+	f.baseStep = NewStep(f.write, f.remove)
+	return f
+}
+
+// Verify checks the file exists with the same content.
+func (f *FileStep) Verify() error {
+	b, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", f.path, ErrVerifyMissing)
+	} else if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(b); !bytes.Equal(sum[:], f.checksum[:]) {
+		return fmt.Errorf("%s: %w", f.path, ErrVerifyChanged)
+	}
+	return nil
+}
+
+// Repair rewrites the file if it drifted.
+func (f *FileStep) Repair() error {
+	if f.Verify() == nil {
+		return nil
+	}
+	f.Reset()
+	return f.Do()
+}
+
+// SetBackup sets the store backing up the file before it is overwritten.
+// The backup is recorded in the state of run until undone, so that Undo restores it after restart.
+// Without the record, Undo fails with ErrFileNoBackup rather than guessing the backup.
+func (f *FileStep) SetBackup(store *backup.Store) *FileStep {
+	f.backup = store
+	return f
+}
+
+func (f *FileStep) write() error {
+	if !f.recorded {
+		if err := f.record(); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(f.path, f.content, f.perm)
+}
+
+// record keeps what is at path before the first write, so that a repeated write,
+// e.g. on repair, does not back up the drifted file over the original one.
+// A backup already recorded in the state of run is kept as well.
+func (f *FileStep) record() error {
+	if f.backup != nil {
+		if id, err := f.lastBackup(); err == nil {
+			f.existed, f.saved, f.recorded = id != "", id, true
+			return nil
+		}
+	}
+	if _, err := os.Lstat(f.path); err == nil {
+		f.existed = true
+	} else if os.IsNotExist(err) {
+		f.existed = false
+	} else {
+		return err
+	}
+	f.saved = ""
+	if f.backup != nil {
+		e, err := f.backup.Save(f.path)
+		if err == nil {
+			f.saved = e.ID
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if f.state != nil {
+			f.state.Set(f.backupKey(), f.saved)
+		}
+	}
+	f.recorded = true
+	return nil
+}
+
+func (f *FileStep) remove() error {
+	if f.backup != nil && !f.recorded {
+		id, err := f.lastBackup()
+		if err != nil {
+			return err
+		}
+		f.existed, f.saved = id != "", id
+	}
+	var err error
+	if f.saved != "" {
+		err = f.backup.Restore(f.saved)
+	} else if !f.existed {
+		err = removeIfExist(f.path)
+	}
+	if err != nil {
+		return err
+	}
+	f.recorded = false
+	if f.state != nil {
+		f.state.Delete(f.backupKey())
+	}
+	return nil
+}
+
+// lastBackup return the backup recorded in the state of run, which is empty if no file was backed up.
+func (f *FileStep) lastBackup() (string, error) {
+	if f.state != nil {
+		if id, ok := Lookup[string](f.state, f.backupKey()); ok {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", f.path, ErrFileNoBackup)
+}
+
+func (f *FileStep) backupKey() string {
+	return "backup:" + f.path
+}
+
+// DirStep is the step creating a directory.
+type DirStep struct {
+	*baseStep
+	path     string
+	perm     os.FileMode
+	created  string
+	recorded bool
+}
+
+// NewDirStep creates step creating the directory at path with perm, along with any parents.
+// Undo removes the directories created by Do if they are empty,
+// or the directory at path if it is empty without an earlier Do.
+func NewDirStep(path string, perm os.FileMode) *DirStep {
+	d := &DirStep{
+		path: path,
+		perm: perm,
+	}
+	d.baseStep = NewStep(d.create, d.remove)
+	return d
+}
+
+// Verify checks the directory exists.
+func (d *DirStep) Verify() error {
+	info, err := os.Stat(d.path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", d.path, ErrVerifyMissing)
+	} else if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %w", d.path, ErrVerifyType)
+	}
+	return nil
+}
+
+// Repair recreates the directory if it drifted, removing the file which replaced it.
+func (d *DirStep) Repair() error {
+	if err := d.Verify(); err == nil {
+		return nil
+	} else if errors.Is(err, ErrVerifyType) {
+		if err := os.Remove(d.path); err != nil {
+			return err
+		}
+	}
+	d.Reset()
+	return d.Do()
+}
+
+func (d *DirStep) create() error {
+	// The missing directories are a chain from path to the outermost one.
+	// The outermost one created by any Do is kept until undone.
+	created := ""
+	for dir := filepath.Clean(d.path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+			break
+		}
+		created = dir
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if !d.recorded || (created != "" && (d.created == "" || len(created) < len(d.created))) {
+		d.created = created
+	}
+	d.recorded = true
+	return os.MkdirAll(d.path, d.perm)
+}
+
+func (d *DirStep) remove() error {
+	if !d.recorded {
+		_, err := removeDir(d.path)
+		return err
+	}
+	if d.created != "" {
+		for dir := filepath.Clean(d.path); ; dir = filepath.Dir(dir) {
+			if removed, err := removeDir(dir); err != nil {
+				return err
+			} else if !removed || dir == d.created || filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	d.created = ""
+	d.recorded = false
+	return nil
+}
+
+// SymlinkStep is the step creating a symbolic link.
+type SymlinkStep struct {
+	*baseStep
+	target string
+	path   string
+}
+
+// NewSymlinkStep creates step creating the symbolic link at path to target.
+// Undo removes the symbolic link.
+func NewSymlinkStep(target string, path string) *SymlinkStep {
+	l := &SymlinkStep{
+		target: target,
+		path:   path,
+	}
+	l.baseStep = NewStep(l.create, l.remove)
+	return l
+}
+
+// Verify checks the symbolic link exists and points to the target.
+func (l *SymlinkStep) Verify() error {
+	target, err := os.Readlink(l.path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", l.path, ErrVerifyMissing)
+	} else if err != nil {
+		return fmt.Errorf("%s: %w", l.path, ErrVerifyType)
+	}
+	if target != l.target {
+		return fmt.Errorf("%s: %w: %s", l.path, ErrVerifyRetargeted, target)
+	}
+	return nil
+}
+
+// Repair recreates the symbolic link if it drifted, removing what replaced it.
+func (l *SymlinkStep) Repair() error {
+	if err := l.Verify(); err == nil {
+		return nil
+	} else if errors.Is(err, ErrVerifyRetargeted) || errors.Is(err, ErrVerifyType) {
+		if err := os.Remove(l.path); err != nil {
+			return err
+		}
+	}
+	l.Reset()
+	return l.Do()
+}
+
+func (l *SymlinkStep) create() error {
+	return os.Symlink(l.target, l.path)
+}
+
+func (l *SymlinkStep) remove() error {
+	return removeIfExist(l.path)
+}
+
+// removeDir removes the directory at path if it is empty, and reports whether it is gone.
+// A directory which is not empty is kept.
+func removeDir(path string) (bool, error) {
+	if err := removeIfExist(path); err != nil {
+		if entries, readErr := os.ReadDir(path); readErr == nil && len(entries) > 0 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func removeIfExist(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/silver886/installer/backup"
+)
+
+func TestFileStep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	t.Log("Write a file.")
+	f := NewFileStep(path, []byte("content"), 0644)
+	if err := f.Verify(); !errors.Is(err, ErrVerifyMissing) {
+		t.Error("File should be missing.")
+	}
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "content" {
+		t.Error("File should be written.")
+	}
+	if err := f.Verify(); err != nil {
+		t.Error("File should be intact.")
+	}
+
+	t.Log("Verify a changed file.")
+	os.WriteFile(path, []byte("changed"), 0644)
+	if err := f.Verify(); !errors.Is(err, ErrVerifyChanged) {
+		t.Error("File should be changed.")
+	}
+
+	t.Log("Remove a file.")
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Error("File step should be able to undo.")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("File should be removed.")
+	}
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Error("Removing a missing file should succeed.")
+	}
+
+	t.Log("Keep a file which existed before.")
+	os.WriteFile(path, []byte("user"), 0644)
+	f = NewFileStep(path, []byte("content"), 0644)
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Error("File step should be able to undo.")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("File which existed before should be kept.")
+	}
+}
+
+func TestFileStepBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	os.WriteFile(path, []byte("user"), 0600)
+	store := backup.NewStore(filepath.Join(dir, "backup"))
+
+	t.Log("Overwrite a file with backup.")
+	f := NewFileStep(path, []byte("content"), 0644).SetBackup(store)
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	if entries, _ := store.List(); len(entries) != 1 || entries[0].Path != path {
+		t.Error("Overwritten file should be backed up.")
+	}
+
+	t.Log("Restore the overwritten file.")
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Fatal("File step should be able to undo.")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "user" {
+		t.Error("Overwritten file should be restored.")
+	}
+
+	t.Log("Restore the overwritten file after repair.")
+	os.WriteFile(path, []byte("user"), 0600)
+	f = NewFileStep(path, []byte("content"), 0644).SetBackup(store)
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	os.WriteFile(path, []byte("drifted"), 0644)
+	if err := Repair(f); err != nil {
+		t.Fatal("File step should be able to repair.")
+	}
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Fatal("File step should be able to undo.")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "user" {
+		t.Error("File overwritten before repair should be restored.")
+	}
+
+	t.Log("Restore the overwritten file after restart.")
+	os.WriteFile(path, []byte("user"), 0600)
+	state := NewState()
+	f = NewFileStep(path, []byte("content"), 0644).SetBackup(store)
+	f.SetState(state)
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	b, _ := json.Marshal(state)
+	restored := NewState()
+	json.Unmarshal(b, restored)
+	f = NewFileStep(path, []byte("content"), 0644).SetBackup(store)
+	f.SetState(restored)
+	if err := f.Undo(); err != nil {
+		t.Fatal("File step should be able to undo.")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "user" {
+		t.Error("Overwritten file should be restored from the recorded backup.")
+	}
+
+	t.Log("Refuse to guess the backup without record.")
+	os.WriteFile(path, []byte("content"), 0644)
+	if err := NewFileStep(path, []byte("content"), 0644).SetBackup(store).Undo(); !errors.Is(err, ErrFileNoBackup) {
+		t.Errorf("File step should fail with %v, got %v.", ErrFileNoBackup, err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "content" {
+		t.Error("File should be kept without record.")
+	}
+
+	t.Log("Write a new file with backup.")
+	f = NewFileStep(path, []byte("content"), 0644).SetBackup(store)
+	os.Remove(path)
+	f.Reset()
+	if err := f.Do(); err != nil {
+		t.Fatal("File step should be able to do.")
+	}
+	f.Reset()
+	if err := f.Undo(); err != nil {
+		t.Fatal("File step should be able to undo.")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("New file should be removed.")
+	}
+}
+
+func TestDirStep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a", "b")
+
+	t.Log("Create a directory.")
+	d := NewDirStep(path, 0755)
+	if err := d.Do(); err != nil {
+		t.Fatal("Directory step should be able to do.")
+	}
+	if err := d.Verify(); err != nil {
+		t.Error("Directory should be intact.")
+	}
+
+	t.Log("Verify a directory replaced by a file.")
+	os.Remove(path)
+	if err := d.Verify(); !errors.Is(err, ErrVerifyMissing) {
+		t.Error("Directory should be missing.")
+	}
+	os.WriteFile(path, nil, 0644)
+	if err := d.Verify(); !errors.Is(err, ErrVerifyType) {
+		t.Error("Directory should be of another type.")
+	}
+
+	t.Log("Remove a directory.")
+	d.Reset()
+	if err := d.Undo(); err != nil {
+		t.Error("Directory step should be able to undo.")
+	}
+
+	t.Log("Keep a directory which is not empty.")
+	os.MkdirAll(path, 0755)
+	os.WriteFile(filepath.Join(path, "user"), nil, 0644)
+	d.Reset()
+	if err := d.Undo(); err != nil {
+		t.Errorf("Directory step should be able to undo, got %v.", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "user")); err != nil {
+		t.Error("Directory which is not empty should be kept.")
+	}
+
+	t.Log("Remove the parents created along.")
+	os.RemoveAll(filepath.Join(dir, "a"))
+	d = NewDirStep(path, 0755)
+	if err := d.Do(); err != nil {
+		t.Fatal("Directory step should be able to do.")
+	}
+	d.Reset()
+	if err := d.Undo(); err != nil {
+		t.Errorf("Directory step should be able to undo, got %v.", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Error("Parent created along should be removed.")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("Parent which existed before should be kept.")
+	}
+
+	t.Log("Keep a directory which existed before.")
+	os.MkdirAll(path, 0755)
+	d = NewDirStep(path, 0755)
+	if err := d.Do(); err != nil {
+		t.Fatal("Directory step should be able to do.")
+	}
+	d.Reset()
+	if err := d.Undo(); err != nil {
+		t.Errorf("Directory step should be able to undo, got %v.", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("Directory which existed before should be kept.")
+	}
+}
+
+func TestSymlinkStep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "link")
+
+	t.Log("Create a symbolic link.")
+	l := NewSymlinkStep("a", path)
+	if err := l.Do(); err != nil {
+		t.Skip("Symbolic link is not supported.")
+	}
+	if err := l.Verify(); err != nil {
+		t.Error("Symbolic link should be intact.")
+	}
+
+	t.Log("Verify a retargeted symbolic link.")
+	os.Remove(path)
+	os.Symlink("b", path)
+	if err := l.Verify(); !errors.Is(err, ErrVerifyRetargeted) {
+		t.Error("Symbolic link should be retargeted.")
+	}
+	os.Remove(path)
+	os.WriteFile(path, nil, 0644)
+	if err := l.Verify(); !errors.Is(err, ErrVerifyType) {
+		t.Error("Symbolic link should be of another type.")
+	}
+
+	t.Log("Remove a symbolic link.")
+	l.Reset()
+	if err := l.Undo(); err != nil {
+		t.Error("Symbolic link step should be able to undo.")
+	}
+	if err := l.Verify(); !errors.Is(err, ErrVerifyMissing) {
+		t.Error("Symbolic link should be missing.")
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/silver886/installer
 
-go 1.14
+go 1.18
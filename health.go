@@ -0,0 +1,37 @@
+package installer
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+// HealthCommand checks the command exits successfully.
+func HealthCommand(name string, args ...string) func() error {
+	return func() error {
+		out, err := exec.Command(name, args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %w: %s", name, err, out)
+		}
+		return nil
+	}
+}
+
+// HealthHTTP checks the endpoint at url responds with a 2xx status within timeout.
+func HealthHTTP(url string, timeout time.Duration) func() error {
+	return func() error {
+		client := &http.Client{
+			Timeout: timeout,
+		}
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%s: %w: %s", url, ErrHealthStatus, resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package installer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHealthHTTP(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Log("Probe a healthy endpoint.")
+	if err := HealthHTTP(server.URL, time.Second)(); err != nil {
+		t.Error("Endpoint should be healthy.")
+	}
+
+	t.Log("Probe an unhealthy endpoint.")
+	status = http.StatusServiceUnavailable
+	if err := HealthHTTP(server.URL, time.Second)(); !errors.Is(err, ErrHealthStatus) {
+		t.Errorf("Endpoint should be unhealthy, got %v.", err)
+	}
+}
+
+func TestHealthCommand(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip("Executable is not found.")
+	}
+	if err := HealthCommand(exe, "-test.run=^$")(); err != nil {
+		t.Errorf("Command should be healthy, got %v.", err)
+	}
+	if err := HealthCommand(exe, "-test.unknown")(); err == nil {
+		t.Error("Command should be unhealthy.")
+	}
+}
@@ -0,0 +1,53 @@
+package installertest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/silver886/installer"
+)
+
+// ChaosOptions configures RunChaos.
+// A zero seed is replaced by the current time, and zero runs by 1.
+type ChaosOptions struct {
+	Seed            int64
+	Runs            int
+	DoProbability   float64
+	UndoProbability float64
+}
+
+// RunChaos triggers the doer of trees built by build under chaos, each run with a new seed.
+// It checks the steps done by a failed run are all undone, unless an undoer is failed by chaos,
+// in which case the fault must be reported. The seed is logged when a run fails.
+func RunChaos(t *testing.T, options ChaosOptions, build func(*Recorder) installer.Stepper) {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	runs := options.Runs
+	if runs <= 0 {
+		runs = 1
+	}
+	for i := 0; i < runs; i++ {
+		seed := seed + int64(i)
+		t.Run(fmt.Sprintf("Seed %d", seed), func(t *testing.T) {
+			r := NewRecorder()
+			c := installer.NewChaos(build(r), seed, options.DoProbability, options.UndoProbability)
+			err := c.Do()
+			switch {
+			case err == nil:
+			case c.UndoInjected() > 0:
+				if !errors.Is(err, installer.ErrChaosInjected) {
+					t.Errorf("Injected fault should be reported, got %v.", err)
+				}
+			default:
+				AssertNoLeaks(t, r)
+			}
+			if t.Failed() {
+				t.Logf("Chaos failed with seed %d, calls %v.", seed, r.Strings())
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package installertest
+
+import (
+	"testing"
+
+	"github.com/silver886/installer"
+)
+
+func TestRunChaos(t *testing.T) {
+	t.Log("Run chaos on a steps with rollback.")
+	RunChaos(t, ChaosOptions{
+		Seed:            1,
+		Runs:            20,
+		DoProbability:   0.3,
+		UndoProbability: 0.1,
+	}, func(r *Recorder) installer.Stepper {
+		return installer.NewSteps([]installer.Stepper{
+			r.Step("a"),
+			installer.NewSteps([]installer.Stepper{
+				r.Step("b"),
+				r.Step("c"),
+			}).SetPolicy(installer.RollbackOnError),
+			r.Step("d"),
+		}).SetPolicy(installer.RollbackOnError)
+	})
+}
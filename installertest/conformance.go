@@ -0,0 +1,152 @@
+package installertest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/silver886/installer"
+)
+
+// Factory creates a fresh stepper whose doer returns doErr and undoer returns undoErr.
+type Factory func(doErr error, undoErr error) installer.Stepper
+
+var (
+	errDo   = errors.New("conformance do")
+	errUndo = errors.New("conformance undo")
+
+	executedErrors = []error{
+		installer.ErrStepExecuted,
+		installer.ErrStepsExecuted,
+		installer.ErrConditionalExecuted,
+		installer.ErrParallelExecuted,
+	}
+	notExecutedErrors = []error{
+		installer.ErrStepNotExecuted,
+		installer.ErrStepsNotExecuted,
+		installer.ErrConditionalNotExecuted,
+		installer.ErrParallelNotExecuted,
+	}
+)
+
+// Conformance checks the steppers created by factory follow the contract of Stepper.
+func Conformance(t *testing.T, factory Factory) {
+	t.Run("Fresh", func(t *testing.T) {
+		s := factory(nil, nil)
+		assertStatus(t, s, 0, false)
+		if err := s.Error(); !isAny(err, notExecutedErrors) {
+			t.Errorf("Error of fresh stepper should be a not executed error, got %v.", err)
+		}
+		if s.Step() != 0 {
+			t.Error("Step of fresh stepper should be 0.")
+		}
+	})
+
+	t.Run("Do", func(t *testing.T) {
+		s := factory(nil, nil)
+		if err := s.Do(); err != nil {
+			t.Fatalf("Do should succeed, got %v.", err)
+		}
+		assertStatus(t, s, 1, true)
+		if err := s.Error(); err != nil {
+			t.Errorf("Error of done stepper should be nil, got %v.", err)
+		}
+		if err := s.Do(); !isAny(err, executedErrors) {
+			t.Errorf("Do twice should return an executed error, got %v.", err)
+		}
+		if err := s.Undo(); !isAny(err, executedErrors) {
+			t.Errorf("Undo after Do should return an executed error, got %v.", err)
+		}
+	})
+
+	t.Run("Do failed", func(t *testing.T) {
+		s := factory(errDo, nil)
+		if err := s.Do(); !errors.Is(err, errDo) {
+			t.Fatalf("Do should return the error of doer, got %v.", err)
+		}
+		assertProgress(t, s)
+		if s.Action() != 1 {
+			t.Error("Action of failed stepper should be 1.")
+		}
+		if err := s.Error(); !errors.Is(err, errDo) {
+			t.Errorf("Error should return the error of doer, got %v.", err)
+		}
+	})
+
+	t.Run("Undo", func(t *testing.T) {
+		s := factory(nil, nil)
+		if err := s.Undo(); err != nil {
+			t.Fatalf("Undo should succeed, got %v.", err)
+		}
+		assertStatus(t, s, -1, true)
+		if err := s.Error(); err != nil {
+			t.Errorf("Error of undone stepper should be nil, got %v.", err)
+		}
+		if err := s.Undo(); !isAny(err, executedErrors) {
+			t.Errorf("Undo twice should return an executed error, got %v.", err)
+		}
+		if err := s.Do(); !isAny(err, executedErrors) {
+			t.Errorf("Do after Undo should return an executed error, got %v.", err)
+		}
+	})
+
+	t.Run("Undo failed", func(t *testing.T) {
+		s := factory(nil, errUndo)
+		if err := s.Undo(); !errors.Is(err, errUndo) {
+			t.Fatalf("Undo should return the error of undoer, got %v.", err)
+		}
+		assertProgress(t, s)
+		if err := s.Error(); !errors.Is(err, errUndo) {
+			t.Errorf("Error should return the error of undoer, got %v.", err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		s := factory(errDo, nil)
+		s.Do()
+		s.Reset()
+		assertStatus(t, s, 0, false)
+		if err := s.Error(); !isAny(err, notExecutedErrors) {
+			t.Errorf("Reset should clear the error, got %v.", err)
+		}
+		if err := s.Do(); !errors.Is(err, errDo) {
+			t.Errorf("Reset stepper should be able to do again, got %v.", err)
+		}
+		s.Reset()
+		if err := s.Undo(); err != nil {
+			t.Errorf("Reset stepper should be able to undo, got %v.", err)
+		}
+	})
+}
+
+func assertStatus(t *testing.T, s installer.Stepper, action int, fin bool) {
+	t.Helper()
+	if s.Action() != action {
+		t.Errorf("Action should be %d, got %d.", action, s.Action())
+	}
+	if s.Fin() != fin {
+		t.Errorf("Fin should be %v, got %v.", fin, s.Fin())
+	}
+	if p := s.Progress(); fin && p != 1 || !fin && action == 0 && p != 0 {
+		t.Errorf("Progress should match the status, got %v.", p)
+	}
+	if s.Step() < 0 {
+		t.Error("Step should be non-negative.")
+	}
+	assertProgress(t, s)
+}
+
+func assertProgress(t *testing.T, s installer.Stepper) {
+	t.Helper()
+	if p := s.Progress(); p < 0 || p > 1 {
+		t.Errorf("Progress should be in range of 0~1, got %v.", p)
+	}
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package installertest
+
+import (
+	"testing"
+
+	"github.com/silver886/installer"
+)
+
+func newStep(doErr error, undoErr error) *installer.Step {
+	return installer.NewStep(
+		func() error { return doErr },
+		func() error { return undoErr },
+	)
+}
+
+func TestConformance(t *testing.T) {
+	var test = []struct {
+		name    string
+		factory Factory
+	}{
+		{
+			name: "Step",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return newStep(doErr, undoErr)
+			},
+		},
+		{
+			name: "Steps",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return installer.NewSteps([]installer.Stepper{
+					newStep(nil, nil),
+					newStep(doErr, undoErr),
+				})
+			},
+		},
+		{
+			name: "Parallel",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return installer.NewParallel([]installer.Stepper{
+					newStep(nil, nil),
+					newStep(doErr, undoErr),
+				})
+			},
+		},
+		{
+			name: "Conditional",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return installer.When(
+					func() (bool, string) { return true, "" },
+					newStep(doErr, undoErr),
+				)
+			},
+		},
+		{
+			name: "TypedStep",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				s := installer.NewTypedStep(
+					func() (int, error) { return 0, doErr },
+					func(int) error { return undoErr },
+				)
+				s.SetValue(0)
+				return s
+			},
+		},
+		{
+			name: "TwoPhase",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return installer.NewTwoPhase(
+					func() error { return doErr },
+					func() error { return nil },
+					func() error { return nil },
+					func() error { return undoErr },
+				)
+			},
+		},
+		{
+			name: "Optional",
+			factory: func(doErr error, undoErr error) installer.Stepper {
+				return installer.NewOptional(newStep(doErr, undoErr))
+			},
+		},
+	}
+
+	t.Log("Check the steppers of installer.")
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			Conformance(t, tt.factory)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package installertest provides fake steppers and assertions for testing installers.
+package installertest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/silver886/installer"
+)
+
+// Call is a recorded call of doer or undoer.
+type Call struct {
+	Name   string
+	Action int
+	Err    error
+}
+
+// String return the call as "do name" or "undo name".
+func (c Call) String() string {
+	if c.Action < 0 {
+		return "undo " + c.Name
+	}
+	return "do " + c.Name
+}
+
+// Recorder records the calls of its steps in order.
+type Recorder struct {
+	mutex *sync.Mutex
+	calls []Call
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Calls return the recorded calls.
+func (r *Recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Strings return the recorded calls as strings.
+func (r *Recorder) Strings() []string {
+	calls := r.Calls()
+	strs := make([]string, len(calls))
+	for i, c := range calls {
+		strs[i] = c.String()
+	}
+	return strs
+}
+
+// Reset clears the recorded calls.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = nil
+}
+
+// Step creates a step recording its calls.
+func (r *Recorder) Step(name string) *installer.Step {
+	return r.NewStep(name, nil, nil)
+}
+
+// NewStep creates a step recording its calls, which triggers doer and undoer if any.
+func (r *Recorder) NewStep(name string, doer func() error, undoer func() error) *installer.Step {
+	return installer.NewStep(
+		r.record(name, 1, doer),
+		r.record(name, -1, undoer),
+	)
+}
+
+// FailingStep creates a recording step whose doer fails with err on the nth call.
+func (r *Recorder) FailingStep(name string, n int, err error) *installer.Step {
+	return r.NewStep(name, FailOn(n, err), nil)
+}
+
+// FailingUndoStep creates a recording step whose undoer fails with err on the nth call.
+func (r *Recorder) FailingUndoStep(name string, n int, err error) *installer.Step {
+	return r.NewStep(name, nil, FailOn(n, err))
+}
+
+// SlowStep creates a recording step whose doer and undoer take duration.
+func (r *Recorder) SlowStep(name string, duration time.Duration) *installer.Step {
+	return r.NewStep(name, Sleep(duration), Sleep(duration))
+}
+
+func (r *Recorder) record(name string, action int, f func() error) func() error {
+	return func() error {
+		var err error
+		if f != nil {
+			err = f()
+		}
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.calls = append(r.calls, Call{
+			Name:   name,
+			Action: action,
+			Err:    err,
+		})
+		return err
+	}
+}
+
+// FailOn creates a function which fails with err on the nth call, and succeeds otherwise.
+func FailOn(n int, err error) func() error {
+	mutex := &sync.Mutex{}
+	count := 0
+	return func() error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		count++
+		if count == n {
+			return err
+		}
+		return nil
+	}
+}
+
+// Sleep creates a function which takes duration and succeeds.
+func Sleep(duration time.Duration) func() error {
+	return func() error {
+		time.Sleep(duration)
+		return nil
+	}
+}
+
+// AssertCalls checks the recorded calls are exactly calls in "do name" or "undo name" form.
+func AssertCalls(t testing.TB, r *Recorder, calls ...string) {
+	t.Helper()
+	if got := r.Strings(); !equal(got, calls) {
+		t.Errorf("Recorder should have calls %v, got %v.", calls, got)
+	}
+}
+
+// AssertUndoneInReverse checks the undone steps are undone in reverse order of being done.
+func AssertUndoneInReverse(t testing.TB, r *Recorder) {
+	t.Helper()
+	var done, undone []string
+	for _, c := range r.Calls() {
+		if c.Err != nil {
+			continue
+		}
+		if c.Action > 0 {
+			done = append(done, c.Name)
+		} else if contains(done, c.Name) {
+			undone = append(undone, c.Name)
+		}
+	}
+	var want []string
+	for i := len(done) - 1; i >= 0; i-- {
+		if contains(undone, done[i]) && !contains(want, done[i]) {
+			want = append(want, done[i])
+		}
+	}
+	if !equal(undone, want) {
+		t.Errorf("Steps should be undone in order %v, got %v.", want, undone)
+	}
+}
+
+// AssertNoLeaks checks every successfully done step is successfully undone afterwards.
+func AssertNoLeaks(t testing.TB, r *Recorder) {
+	t.Helper()
+	applied := map[string]int{}
+	var names []string
+	for _, c := range r.Calls() {
+		if c.Err != nil {
+			continue
+		}
+		if _, ok := applied[c.Name]; !ok {
+			names = append(names, c.Name)
+		}
+		if c.Action > 0 {
+			applied[c.Name]++
+		} else if applied[c.Name] > 0 {
+			applied[c.Name]--
+		}
+	}
+	for _, name := range names {
+		if applied[name] > 0 {
+			t.Errorf("Step %q is done but not undone.", name)
+		}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package installertest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/silver886/installer"
+)
+
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failed = true
+}
+
+func TestRecorder(t *testing.T) {
+	t.Log("Record calls of steps.")
+	r := NewRecorder()
+	s := installer.NewSteps([]installer.Stepper{
+		r.Step("a"),
+		r.Step("b"),
+	})
+	s.Do()
+	AssertCalls(t, r, "do a", "do b")
+	if c := r.Calls()[1]; c.Name != "b" || c.Action != 1 || c.Err != nil {
+		t.Error("Call should be recorded.")
+	}
+	r.Reset()
+	AssertCalls(t, r)
+}
+
+func TestFailingStep(t *testing.T) {
+	errFail := errors.New("fail")
+
+	t.Log("Fail a step on the nth call.")
+	r := NewRecorder()
+	s := r.FailingStep("a", 2, errFail)
+	if err := s.Do(); err != nil {
+		t.Error("First call should succeed.")
+	}
+	s.Reset()
+	if err := s.Do(); err != errFail {
+		t.Error("Second call should fail.")
+	}
+	if c := r.Calls()[1]; c.Err != errFail {
+		t.Error("Failure should be recorded.")
+	}
+
+	t.Log("Fail an undoer on the nth call.")
+	u := r.FailingUndoStep("b", 1, errFail)
+	if err := u.Undo(); err != errFail {
+		t.Error("First call should fail.")
+	}
+}
+
+func TestSlowStep(t *testing.T) {
+	t.Log("Slow down a step.")
+	r := NewRecorder()
+	start := time.Now()
+	r.SlowStep("a", 10*time.Millisecond).Do()
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("Step should be slow.")
+	}
+}
+
+func TestAssertUndoneInReverse(t *testing.T) {
+	t.Log("Check a rollback.")
+	r := NewRecorder()
+	s := installer.NewSteps([]installer.Stepper{
+		r.Step("a"),
+		r.Step("b"),
+		r.FailingStep("c", 1, errors.New("")),
+	}).SetPolicy(installer.RollbackOnError)
+	s.Do()
+	AssertCalls(t, r, "do a", "do b", "do c", "undo b", "undo a")
+	AssertUndoneInReverse(t, r)
+	AssertNoLeaks(t, r)
+
+	var test = []struct {
+		calls  []string
+		failed bool
+	}{
+		{
+			calls:  []string{"do a", "do b", "undo a", "undo b"},
+			failed: true,
+		},
+		{
+			calls:  []string{"do a", "do b", "undo b"},
+			failed: false,
+		},
+	}
+
+	t.Log("Check a wrong rollback.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			r := NewRecorder()
+			steps := map[string]*installer.Step{}
+			for _, c := range tt.calls {
+				var action, name string
+				fmt.Sscan(c, &action, &name)
+				if steps[name] == nil {
+					steps[name] = r.Step(name)
+				}
+				steps[name].Reset()
+				if action == "do" {
+					steps[name].Do()
+				} else {
+					steps[name].Undo()
+				}
+			}
+			ft := &fakeT{TB: t}
+			AssertUndoneInReverse(ft, r)
+			if ft.failed != tt.failed {
+				t.Error("Order of undo should be checked.")
+			}
+		})
+	}
+}
+
+func TestAssertNoLeaks(t *testing.T) {
+	t.Log("Check leaked steps.")
+	r := NewRecorder()
+	s := installer.NewSteps([]installer.Stepper{
+		r.Step("a"),
+		r.FailingUndoStep("b", 1, errors.New("")),
+		r.FailingStep("c", 1, errors.New("")),
+	}).SetPolicy(installer.RollbackOnError)
+	s.Do()
+	ft := &fakeT{TB: t}
+	AssertNoLeaks(ft, r)
+	if !ft.failed {
+		t.Error("Leaked step should be reported.")
+	}
+}
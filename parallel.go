@@ -0,0 +1,161 @@
+package installer
+
+import (
+	"sync"
+)
+
+// Parallel is the set of steppers executed concurrently.
+type Parallel struct {
+	mutex    *sync.Mutex
+	step     int
+	err      error
+	state    *State
+	steppers []Stepper
+}
+
+// NewParallel creates a set of concurrent steppers with given steppers.
+func NewParallel(steppers []Stepper) *Parallel {
+	return &Parallel{
+		mutex:    &sync.Mutex{},
+		steppers: steppers,
+	}
+}
+
+// Do triggers all steppers' doer concurrently.
+func (p *Parallel) Do() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err := p.checkSteppers(); err != nil {
+		return err
+	}
+	if p.step != 0 {
+		return ErrParallelExecuted
+	}
+	return p.run(1, func(ss Stepper) error { return execute(ss, 1) })
+}
+
+// Undo triggers all steppers' undoer concurrently.
+func (p *Parallel) Undo() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err := p.checkSteppers(); err != nil {
+		return err
+	}
+	if p.step != 0 {
+		return ErrParallelExecuted
+	}
+	return p.run(-1, func(ss Stepper) error { return execute(ss, -1) })
+}
+
+// Error return the errors during executing steppers.
+func (p *Parallel) Error() error {
+	if p.step == 0 {
+		return ErrParallelNotExecuted
+	}
+	return p.err
+}
+
+// Action return current action of parallel.
+func (p *Parallel) Action() int {
+	return p.step
+}
+
+// Fin return the status of parallel.
+func (p *Parallel) Fin() bool {
+	return p.step != 0 && p.Step() == len(p.steppers)
+}
+
+// Step return the number of finished steppers.
+func (p *Parallel) Step() int {
+	if p.step == 0 {
+		return 0
+	}
+	n := 0
+	for _, ss := range p.steppers {
+		if ss.Fin() {
+			n++
+		}
+	}
+	return n
+}
+
+// Progress return the progress status of steppers weighted by their weight.
+func (p *Parallel) Progress() float64 {
+	if err := p.checkSteppers(); err != nil || p.step == 0 {
+		return 0
+	}
+	total := p.Weight()
+	if total <= 0 {
+		return 0
+	}
+	var progress float64
+	for _, ss := range p.steppers {
+		progress += weightOf(ss) * ss.Progress()
+	}
+	return progress / total
+}
+
+// Reset clears the status.
+func (p *Parallel) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, ss := range p.steppers {
+		ss.Reset()
+	}
+	p.step = 0
+	p.err = nil
+}
+
+func (p *Parallel) revertPartial() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.step == 0 {
+		return nil
+	}
+	var errs errorList
+	for _, ss := range p.steppers {
+		if ss.Action() == p.step && ss.Error() == nil {
+			ss.Reset()
+			errs = errs.append(execute(ss, -p.step))
+		} else if pp, ok := ss.(partial); ok {
+			errs = errs.append(pp.revertPartial())
+		}
+	}
+	return errs.err()
+}
+
+// run applies all steppers for action and waits for them.
+// Only failures of non-optional steppers are returned.
+func (p *Parallel) run(action int, apply func(Stepper) error) error {
+	if p.state == nil {
+		p.setState(NewState())
+	}
+	p.step = action
+	errs := make([]error, len(p.steppers))
+	wg := &sync.WaitGroup{}
+	for i, ss := range p.steppers {
+		wg.Add(1)
+		go func(i int, ss Stepper) {
+			defer wg.Done()
+			errs[i] = apply(ss)
+		}(i, ss)
+	}
+	wg.Wait()
+
+	var all, failed errorList
+	for i, err := range errs {
+		all = all.append(err)
+		if !isOptional(p.steppers[i]) {
+			failed = failed.append(err)
+		}
+	}
+	p.err = all.err()
+	return failed.err()
+}
+
+func (p *Parallel) checkSteppers() error {
+	if len(p.steppers) == 0 {
+		return ErrParallelNoStepper
+	}
+	return nil
+}
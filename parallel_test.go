@@ -0,0 +1,142 @@
+package installer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestParallelNew(t *testing.T) {
+	t.Log("Create a parallel.")
+	var normalTest = [][]Stepper{
+		{
+			NewStep(
+				func() error { return nil },
+				func() error { return nil },
+			),
+		},
+		{},
+		nil,
+	}
+	for _, tt := range normalTest {
+		t.Run("Normal", func(t *testing.T) {
+			if p := NewParallel(tt); p == nil {
+				t.Error("Parallel should be able to create.")
+			}
+		})
+	}
+}
+
+func TestParallelDo(t *testing.T) {
+	var test = []struct {
+		steppers []Stepper
+		result   error
+	}{
+		{
+			steppers: []Stepper{
+				NewStep(
+					func() error { return nil },
+					func() error { return nil },
+				),
+				NewStep(
+					func() error { return nil },
+					func() error { return nil },
+				),
+			},
+			result: nil,
+		},
+		{
+			steppers: []Stepper{
+				NewStep(
+					func() error { return nil },
+					func() error { return nil },
+				),
+				NewStep(
+					func() error { return errors.New("") },
+					func() error { return nil },
+				),
+			},
+			result: errors.New(""),
+		},
+	}
+
+	t.Log("Normally do a parallel.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			p := &Parallel{
+				mutex:    &sync.Mutex{},
+				steppers: tt.steppers,
+			}
+			if err := p.Do(); err != tt.result && err.Error() != tt.result.Error() {
+				t.Error("Parallel should be able to do.")
+			}
+			if !p.Fin() || p.Step() != 2 || p.Progress() != 1 || p.Action() != 1 {
+				t.Error("Parallel should be finished.")
+			}
+			p.Reset()
+			if p.Fin() || p.Progress() != 0 || p.Action() != 0 {
+				t.Error("Parallel should be cleared.")
+			}
+		})
+	}
+
+	t.Log("Do an emtpy parallel.")
+	t.Run("Emtpy", func(t *testing.T) {
+		p := &Parallel{
+			mutex: &sync.Mutex{},
+		}
+		if err := p.Do(); err != ErrParallelNoStepper {
+			t.Error("Parallel should not be able to do.")
+		}
+	})
+
+	t.Log("Do an executed parallel.")
+	for _, tt := range test {
+		t.Run("Executed", func(t *testing.T) {
+			p := &Parallel{
+				mutex:    &sync.Mutex{},
+				steppers: tt.steppers,
+				step:     1,
+			}
+			if err := p.Do(); err != ErrParallelExecuted {
+				t.Error("Parallel should not be able to do.")
+			}
+		})
+	}
+
+	t.Log("Do a parallel with a failed optional stepper.")
+	t.Run("Optional", func(t *testing.T) {
+		p := NewParallel([]Stepper{
+			NewOptional(NewStep(
+				func() error { return errors.New("") },
+				nil,
+			)),
+		})
+		if err := p.Do(); err != nil {
+			t.Error("Failed optional stepper should not fail the parallel.")
+		}
+		if err := p.Error(); err == nil {
+			t.Error("Error of optional stepper should be able to get.")
+		}
+	})
+}
+
+func TestParallelUndo(t *testing.T) {
+	t.Log("Normally undo a parallel.")
+	errA := errors.New("a")
+	errB := errors.New("b")
+	p := NewParallel([]Stepper{
+		NewStep(nil, func() error { return errA }),
+		NewStep(nil, func() error { return errB }),
+	})
+	if err := p.Error(); err != ErrParallelNotExecuted {
+		t.Error("Error should not be able to get.")
+	}
+	err := p.Undo()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("Parallel should collect all errors.")
+	}
+	if p.Action() != -1 || !errors.Is(p.Error(), errA) {
+		t.Error("Parallel should be undone.")
+	}
+}
@@ -0,0 +1,45 @@
+package installer
+
+// ErrorPolicy decides how steps handles a failed stepper.
+type ErrorPolicy int
+
+const (
+	// AbortOnError stops at the first failed stepper.
+	AbortOnError ErrorPolicy = iota
+	// ContinueOnError executes every stepper and collects the failures.
+	ContinueOnError
+	// RollbackOnError stops at the first failed stepper and reverts the executed steppers.
+	RollbackOnError
+)
+
+// Optional is a stepper whose failure does not stop the steps.
+type Optional struct {
+	Stepper
+}
+
+// NewOptional marks stepper as optional.
+func NewOptional(stepper Stepper) *Optional {
+	return &Optional{
+		Stepper: stepper,
+	}
+}
+
+func isOptional(stepper Stepper) bool {
+	_, ok := stepper.(*Optional)
+	return ok
+}
+
+// execute triggers the doer of stepper if action is positive, otherwise the undoer.
+func execute(stepper Stepper, action int) error {
+	if action > 0 {
+		return stepper.Do()
+	}
+	return stepper.Undo()
+}
+
+func (o *Optional) revertPartial() error {
+	if p, ok := o.Stepper.(partial); ok {
+		return p.revertPartial()
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package installer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func recordStep(calls *[]string, name string, doErr, undoErr error) *Step {
+	return NewStep(
+		func() error {
+			*calls = append(*calls, "do "+name)
+			return doErr
+		},
+		func() error {
+			*calls = append(*calls, "undo "+name)
+			return undoErr
+		},
+	)
+}
+
+func TestStepsPolicy(t *testing.T) {
+	errDo := errors.New("do")
+	var test = []struct {
+		policy ErrorPolicy
+		calls  []string
+		step   int
+	}{
+		{
+			policy: AbortOnError,
+			calls:  []string{"do a", "do b"},
+			step:   2,
+		},
+		{
+			policy: ContinueOnError,
+			calls:  []string{"do a", "do b", "do c"},
+			step:   3,
+		},
+		{
+			policy: RollbackOnError,
+			calls:  []string{"do a", "do b", "undo a"},
+			step:   0,
+		},
+	}
+
+	t.Log("Do a steps with a failed stepper.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			var calls []string
+			s := NewSteps([]Stepper{
+				recordStep(&calls, "a", nil, nil),
+				recordStep(&calls, "b", errDo, nil),
+				recordStep(&calls, "c", nil, nil),
+			}).SetPolicy(tt.policy)
+			if err := s.Do(); !errors.Is(err, errDo) {
+				t.Error("Steps should return the error of failed stepper.")
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("Steps should call %v, got %v.", tt.calls, calls)
+			}
+			if s.Step() != tt.step {
+				t.Error("Step status of steps should follow the policy.")
+			}
+			if err := s.Error(); !errors.Is(err, errDo) {
+				t.Error("Error of failed stepper should be able to get.")
+			}
+			if err := s.Do(); err != ErrStepsExecuted {
+				t.Error("Steps should not be able to do.")
+			}
+		})
+	}
+
+	t.Log("Undo a steps with a failed stepper by rollback.")
+	t.Run("Rollback undo", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			recordStep(&calls, "b", nil, errDo),
+		}).SetPolicy(RollbackOnError)
+		if err := s.Undo(); !errors.Is(err, errDo) {
+			t.Error("Steps should return the error of failed stepper.")
+		}
+		if want := []string{"undo a", "undo b", "do a"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+	})
+
+	t.Log("Collect the errors of rollback.")
+	t.Run("Rollback error", func(t *testing.T) {
+		errUndo := errors.New("undo")
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, errUndo),
+			recordStep(&calls, "b", errDo, nil),
+		}).SetPolicy(RollbackOnError)
+		err := s.Do()
+		if errs, ok := err.(*MultiError); !ok || len(errs.Errors) != 2 {
+			t.Fatal("Steps should collect all errors.")
+		}
+		if !errors.Is(err, errDo) || !errors.Is(err, errUndo) {
+			t.Error("Steps should contain both errors.")
+		}
+	})
+
+	t.Log("Roll back the executed part of a failed group.")
+	t.Run("Rollback group", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			NewSteps([]Stepper{
+				recordStep(&calls, "b", nil, nil),
+				recordStep(&calls, "c", errDo, nil),
+			}),
+			recordStep(&calls, "d", nil, nil),
+		}).SetPolicy(RollbackOnError)
+		if err := s.Do(); err != errDo {
+			t.Errorf("Steps should return %v, got %v.", errDo, err)
+		}
+		if want := []string{"do a", "do b", "do c", "undo b", "undo a"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+	})
+
+	t.Log("Roll back the executed part of a failed parallel group.")
+	t.Run("Rollback parallel", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			NewParallel([]Stepper{
+				NewSteps([]Stepper{
+					recordStep(&calls, "b", nil, nil),
+					recordStep(&calls, "c", errDo, nil),
+				}),
+			}),
+		}).SetPolicy(RollbackOnError)
+		if err := s.Do(); err != errDo {
+			t.Errorf("Steps should return %v, got %v.", errDo, err)
+		}
+		if want := []string{"do a", "do b", "do c", "undo b", "undo a"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+	})
+}
+
+func TestOptional(t *testing.T) {
+	errDo := errors.New("do")
+	var test = []ErrorPolicy{
+		AbortOnError,
+		ContinueOnError,
+		RollbackOnError,
+	}
+
+	t.Log("Do a steps with a failed optional stepper.")
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			var calls []string
+			s := NewSteps([]Stepper{
+				recordStep(&calls, "a", nil, nil),
+				NewOptional(recordStep(&calls, "b", errDo, nil)),
+				recordStep(&calls, "c", nil, nil),
+			}).SetPolicy(tt)
+			if err := s.Do(); err != nil {
+				t.Error("Failed optional stepper should not fail the steps.")
+			}
+			if want := []string{"do a", "do b", "do c"}; !reflect.DeepEqual(calls, want) {
+				t.Errorf("Steps should call %v, got %v.", want, calls)
+			}
+			if err := s.Error(); !errors.Is(err, errDo) {
+				t.Error("Error of optional stepper should be able to get.")
+			}
+			if !s.Fin() {
+				t.Error("Steps should be finished.")
+			}
+		})
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+
+	t.Log("Append errors.")
+	var errs errorList
+	errs = errs.append(nil)
+	if errs.err() != nil {
+		t.Error("Empty errors should be nil.")
+	}
+	errs = errs.append(errA)
+	if errs.err() != errA {
+		t.Error("Single error should be unwrapped.")
+	}
+	errs = errs.append(&MultiError{Errors: []error{errB, errA}})
+	if len(errs) != 3 {
+		t.Error("Nested errors should be flattened.")
+	}
+	err := errs.err()
+	if err.Error() != "a; b; a" {
+		t.Error("Messages should be joined.")
+	}
+	if !errors.Is(err, errB) {
+		t.Error("Errors should match any error.")
+	}
+	if err == errA {
+		t.Error("Errors should be comparable.")
+	}
+}
@@ -0,0 +1,25 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/silver886/installer/version"
+)
+
+// VersionPredicate creates predicate which holds if the version given by current satisfies constraint.
+func VersionPredicate(current func() (string, error), constraint *version.Constraint) Predicate {
+	return func() (bool, string) {
+		s, err := current()
+		if err != nil {
+			return false, err.Error()
+		}
+		v, err := version.Parse(s)
+		if err != nil {
+			return false, err.Error()
+		}
+		if !constraint.Check(v) {
+			return false, fmt.Sprintf("Version %s does not satisfy %q", v, constraint)
+		}
+		return true, fmt.Sprintf("Version %s satisfies %q", v, constraint)
+	}
+}
@@ -0,0 +1,37 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/silver886/installer/version"
+)
+
+func TestVersionPredicate(t *testing.T) {
+	errVersion := errors.New("version")
+	constraint := version.MustParseConstraint(">=1.2 <2")
+	var test = []struct {
+		version string
+		err     error
+		ok      bool
+	}{
+		{"1.4.0", nil, true},
+		{"2.0.0", nil, false},
+		{"invalid", nil, false},
+		{"", errVersion, false},
+	}
+	for _, tt := range test {
+		c := When(VersionPredicate(func() (string, error) {
+			return tt.version, tt.err
+		}, constraint), NewStep(func() error { return nil }, nil))
+		if err := c.Do(); err != nil {
+			t.Fatal("Conditional should be able to do.")
+		}
+		if c.Skipped() == tt.ok {
+			t.Errorf("Version %q should be matched as %t.", tt.version, tt.ok)
+		}
+		if c.Reason() == "" {
+			t.Error("Predicate should give the reason.")
+		}
+	}
+}
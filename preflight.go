@@ -0,0 +1,71 @@
+package installer
+
+// Preflighter implements the checks before any stepper in the tree is executed.
+type Preflighter interface {
+	Preflight() error
+}
+
+// Preflight checks the tree of stepper before triggering its doer,
+// and reports all failures at once.
+// Steppers not implementing Preflighter and optional steppers are not checked.
+func Preflight(stepper Stepper) error {
+	if p, ok := stepper.(Preflighter); ok {
+		return p.Preflight()
+	}
+	return nil
+}
+
+// AddPreflight adds checks to run in preflight of step.
+func (s *Step) AddPreflight(checks ...func() error) *Step {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.preflights = append(s.preflights, checks...)
+	return s
+}
+
+// Preflight runs all checks of step.
+func (s *Step) Preflight() error {
+	var errs errorList
+	for _, check := range s.preflights {
+		errs = errs.append(call(check))
+	}
+	return errs.err()
+}
+
+// Preflight checks all steppers.
+func (s *Steps) Preflight() error {
+	return preflightAll(s.steppers)
+}
+
+// Preflight checks all steppers.
+func (p *Parallel) Preflight() error {
+	return preflightAll(p.steppers)
+}
+
+// Preflight checks the stepper if the predicate holds.
+func (c *Conditional) Preflight() error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	if ok, _ := c.predicate(); !ok {
+		return nil
+	}
+	return Preflight(c.stepper)
+}
+
+// Preflight checks the tree.
+func (c *Chaos) Preflight() error {
+	return Preflight(c.Stepper)
+}
+
+func (f *fault) Preflight() error {
+	return Preflight(f.Stepper)
+}
+
+func preflightAll(steppers []Stepper) error {
+	var errs errorList
+	for _, ss := range steppers {
+		errs = errs.append(Preflight(ss))
+	}
+	return errs.err()
+}
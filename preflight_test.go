@@ -0,0 +1,74 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	errC := errors.New("c")
+	pass := func() error { return nil }
+	fail := func(err error) func() error {
+		return func() error { return err }
+	}
+
+	t.Log("Preflight a tree of steppers.")
+	t.Run("Normal", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil).AddPreflight(pass, fail(errA)),
+			NewParallel([]Stepper{
+				recordStep(&calls, "b", nil, nil).AddPreflight(fail(errB)),
+			}),
+			When(
+				func() (bool, string) { return true, "" },
+				NewSteps([]Stepper{
+					recordStep(&calls, "c", nil, nil).AddPreflight(fail(errC)),
+				}),
+			),
+		})
+		err := Preflight(s)
+		if !errors.Is(err, errA) || !errors.Is(err, errB) || !errors.Is(err, errC) {
+			t.Error("Preflight should report all failures.")
+		}
+		if errs, ok := err.(*MultiError); !ok || len(errs.Errors) != 3 {
+			t.Error("Preflight should report failures in one error.")
+		}
+		if len(calls) != 0 || s.Action() != 0 {
+			t.Error("Preflight should not execute any stepper.")
+		}
+	})
+
+	var test = []Stepper{
+		NewStep(nil, nil),
+		NewStep(nil, nil).AddPreflight(pass),
+		NewSteps([]Stepper{
+			NewOptional(NewStep(nil, nil).AddPreflight(fail(errA))),
+		}),
+		When(
+			func() (bool, string) { return false, "" },
+			NewStep(nil, nil).AddPreflight(fail(errA)),
+		),
+		NewChaos(NewStep(nil, nil).AddPreflight(pass), 1, 1, 1),
+	}
+
+	t.Log("Preflight a tree of steppers without failure.")
+	for _, tt := range test {
+		t.Run("Pass", func(t *testing.T) {
+			if err := Preflight(tt); err != nil {
+				t.Error("Preflight should pass.")
+			}
+		})
+	}
+
+	t.Log("Recover a panic in check.")
+	t.Run("Panic", func(t *testing.T) {
+		s := NewStep(nil, nil).AddPreflight(func() error { panic("") })
+		var perr *PanicError
+		if err := Preflight(s); !errors.As(err, &perr) {
+			t.Error("Panic should be recovered as PanicError.")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"time"
+)
+
+// Weighter implements the weight of stepper in progress of its parent.
+// Steppers without weight are weighted as 1.
+type Weighter interface {
+	Weight() float64
+}
+
+// SetWeight sets the weight of step in progress of its parent.
+// Non-positive weight is treated as 1.
+func (s *Step) SetWeight(weight float64) *Step {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.weight = weight
+	return s
+}
+
+// SetEstimate sets the weight of step by the estimated duration of doer.
+func (s *Step) SetEstimate(estimate time.Duration) *Step {
+	return s.SetWeight(estimate.Seconds())
+}
+
+// Weight return the weight of step.
+func (s *Step) Weight() float64 {
+	if s.weight <= 0 {
+		return 1
+	}
+	return s.weight
+}
+
+// Weight return the total weight of steppers.
+func (s *Steps) Weight() float64 {
+	return weightSum(s.steppers)
+}
+
+// Elapsed return the duration since the steps started executing.
+func (s *Steps) Elapsed() time.Duration {
+	if s.started.IsZero() {
+		return 0
+	}
+	return time.Since(s.started)
+}
+
+// ETA return the estimated remaining duration by the observed progress rate.
+// Zero is returned if the rate is unknown yet.
+func (s *Steps) ETA() time.Duration {
+	progress := s.Progress() - s.startProgress
+	if s.started.IsZero() || progress <= 0 {
+		return 0
+	}
+	return time.Duration(float64(s.Elapsed()) * (1 - s.Progress()) / progress)
+}
+
+// Weight return the total weight of steppers.
+func (p *Parallel) Weight() float64 {
+	return weightSum(p.steppers)
+}
+
+// Weight return the weight of stepper.
+func (c *Conditional) Weight() float64 {
+	return weightOf(c.stepper)
+}
+
+// Weight return the weight of stepper.
+func (o *Optional) Weight() float64 {
+	return weightOf(o.Stepper)
+}
+
+// progressOf return the progress of stepper, and a nil stepper counts as done.
+func progressOf(stepper Stepper) float64 {
+	if stepper == nil {
+		return 1
+	}
+	return stepper.Progress()
+}
+
+func weightOf(stepper Stepper) float64 {
+	if w, ok := stepper.(Weighter); ok {
+		return w.Weight()
+	}
+	return 1
+}
+
+func weightSum(steppers []Stepper) float64 {
+	var sum float64
+	for _, ss := range steppers {
+		sum += weightOf(ss)
+	}
+	return sum
+}
@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStepWeight(t *testing.T) {
+	t.Log("Get weight of a step.")
+	var normalTest = []struct {
+		weight float64
+		result float64
+	}{
+		{
+			weight: 0,
+			result: 1,
+		},
+		{
+			weight: -1,
+			result: 1,
+		},
+		{
+			weight: 2.5,
+			result: 2.5,
+		},
+	}
+	for _, tt := range normalTest {
+		t.Run("Normal", func(t *testing.T) {
+			s := NewStep(nil, nil).SetWeight(tt.weight)
+			if s.Weight() != tt.result {
+				t.Error("Weight of step should be the same.")
+			}
+		})
+	}
+
+	t.Log("Set weight of a step by estimate.")
+	t.Run("Estimate", func(t *testing.T) {
+		if s := NewStep(nil, nil).SetEstimate(time.Minute); s.Weight() != 60 {
+			t.Error("Weight of step should be the estimated seconds.")
+		}
+	})
+}
+
+func TestStepsWeightedProgress(t *testing.T) {
+	t.Log("Get weighted progress status.")
+	var normalTest = []struct {
+		step      int
+		innerStep int
+		progress  float64
+	}{
+		{
+			step:     0,
+			progress: 0,
+		},
+		{
+			step:     1,
+			progress: 0.1,
+		},
+		{
+			step:     -2,
+			progress: 0.2,
+		},
+		{
+			step:      3,
+			innerStep: 1,
+			progress:  0.5,
+		},
+		{
+			step:      3,
+			innerStep: 2,
+			progress:  1,
+		},
+	}
+	for _, tt := range normalTest {
+		t.Run("Normal", func(t *testing.T) {
+			done := func() *Step {
+				s := NewStep(func() error { return nil }, func() error { return nil })
+				s.step = 1
+				return s
+			}
+			s := &Steps{
+				mutex: &sync.Mutex{},
+				steppers: []Stepper{
+					done(),
+					done(),
+					&Steps{
+						mutex: &sync.Mutex{},
+						steppers: []Stepper{
+							done().SetWeight(3),
+							done().SetWeight(5),
+						},
+						step: tt.innerStep,
+					},
+				},
+				step: tt.step,
+			}
+			if s.Weight() != 10 {
+				t.Error("Weight of steps should be the sum of steppers.")
+			}
+			if s.Progress() != tt.progress {
+				t.Errorf("Progress should be %v, got %v.", tt.progress, s.Progress())
+			}
+		})
+	}
+
+	t.Log("Get weighted progress status of a running stepper.")
+	t.Run("Running", func(t *testing.T) {
+		var s *Steps
+		var progress float64
+		s = NewSteps([]Stepper{
+			NewStep(func() error { return nil }, nil),
+			NewSteps([]Stepper{
+				NewStep(func() error { return nil }, nil),
+				NewStep(func() error {
+					progress = s.Progress()
+					return nil
+				}, nil).SetWeight(2),
+			}),
+		})
+		if err := s.Do(); err != nil {
+			t.Fatal("Steps should be able to do.")
+		}
+		if progress != 0.5 {
+			t.Errorf("Running stepper should not count as done, got %v.", progress)
+		}
+	})
+
+	t.Log("Get weighted progress status of a parallel.")
+	t.Run("Parallel", func(t *testing.T) {
+		p := NewParallel([]Stepper{
+			NewStep(func() error { return nil }, nil).SetWeight(3),
+			When(
+				func() (bool, string) { return true, "" },
+				NewStep(nil, nil),
+			),
+		})
+		if p.Weight() != 4 {
+			t.Error("Weight of parallel should be the sum of steppers.")
+		}
+		p.Do()
+		if p.Progress() != 0.75 {
+			t.Error("Progress of parallel should be weighted.")
+		}
+	})
+}
+
+func TestStepsETA(t *testing.T) {
+	t.Log("Estimate the remaining duration.")
+	s := NewSteps([]Stepper{
+		NewStep(func() error { return nil }, nil),
+		NewStep(func() error { return nil }, nil),
+	})
+	if s.ETA() != 0 || s.Elapsed() != 0 {
+		t.Error("ETA of non-executed steps should be unknown.")
+	}
+	s.Do()
+	if s.Elapsed() <= 0 {
+		t.Error("Elapsed duration should be recorded.")
+	}
+	if s.ETA() != 0 {
+		t.Error("ETA of finished steps should be zero.")
+	}
+	s.started = time.Now().Add(-time.Minute)
+	s.step = 1
+	if eta := s.ETA(); eta < time.Minute-time.Second || eta > time.Minute+time.Second {
+		t.Errorf("ETA should be about a minute, got %v.", eta)
+	}
+}
@@ -0,0 +1,99 @@
+package installer
+
+// Repairer implements re-applying only the parts of stepper which are drifted or failed.
+type Repairer interface {
+	Repair() error
+}
+
+// Repair re-applies the tree of stepper where it is drifted or failed.
+// A leaf is re-applied if it drifted by Verify, or if it is a not verifiable stepper
+// which is not done successfully.
+func Repair(stepper Stepper) error {
+	if r, ok := stepper.(Repairer); ok {
+		return r.Repair()
+	}
+	if !needsRepair(stepper) {
+		return nil
+	}
+	stepper.Reset()
+	return stepper.Do()
+}
+
+// Repair re-applies the drifted or failed steppers in order.
+// It stops at the first failure unless the policy is ContinueOnError.
+func (s *Steps) Repair() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.checkSteppers(); err != nil {
+		return err
+	}
+	s.ensureState()
+	s.action = 1
+	s.step = 0
+	s.savepoint = ""
+	s.done = make([]bool, len(s.steppers))
+	var errs errorList
+	failed := false
+	for i, ss := range s.steppers {
+		s.err = errs.err()
+		s.step++
+		err := Repair(ss)
+		if err == nil {
+			s.done[i] = true
+			continue
+		}
+		errs = errs.append(err)
+		if isOptional(ss) {
+			continue
+		}
+		failed = true
+		if s.policy != ContinueOnError {
+			break
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return errs.err()
+}
+
+// Repair re-applies the drifted or failed steppers concurrently.
+func (p *Parallel) Repair() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err := p.checkSteppers(); err != nil {
+		return err
+	}
+	return p.run(1, Repair)
+}
+
+// Repair re-applies the stepper if the predicate holds.
+func (c *Conditional) Repair() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.check(); err != nil {
+		return err
+	}
+	c.step = 1
+	if !c.decide() {
+		return nil
+	}
+	return Repair(c.stepper)
+}
+
+// Repair re-applies the stepper.
+func (o *Optional) Repair() error {
+	return Repair(o.Stepper)
+}
+
+// Repair re-applies the tree.
+func (c *Chaos) Repair() error {
+	return Repair(c.Stepper)
+}
+
+func needsRepair(stepper Stepper) bool {
+	if _, ok := stepper.(Verifier); ok {
+		return Verify(stepper) != nil
+	}
+	return stepper.Action() != 1 || stepper.Error() != nil
+}
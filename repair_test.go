@@ -0,0 +1,130 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	dir := t.TempDir()
+	errDo := errors.New("do")
+	var calls []string
+	a := NewFileStep(filepath.Join(dir, "a"), []byte("a"), 0644)
+	b := NewFileStep(filepath.Join(dir, "b"), []byte("b"), 0644)
+	fail := true
+	c := NewStep(func() error {
+		calls = append(calls, "do c")
+		if fail {
+			return errDo
+		}
+		return nil
+	}, nil)
+	s := NewSteps([]Stepper{
+		a,
+		recordStep(&calls, "x", nil, nil),
+		NewParallel([]Stepper{b}),
+		When(func() (bool, string) { return true, "" }, c),
+	})
+
+	t.Log("Do steps with a failure.")
+	if err := s.Do(); err != errDo {
+		t.Fatal("Steps should fail.")
+	}
+
+	t.Log("Repair a drifted and failed installation.")
+	os.Remove(filepath.Join(dir, "b"))
+	calls = nil
+	fail = false
+	if err := s.Repair(); err != nil {
+		t.Fatalf("Steps should be able to repair, got %v.", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"do c"}) {
+		t.Errorf("Only failed steppers should be re-applied, got %v.", calls)
+	}
+	if err := Verify(s); err != nil {
+		t.Errorf("Installation should be intact, got %v.", err)
+	}
+	if s.Error() != nil || !s.Fin() || s.Progress() != 1 {
+		t.Error("Steps should be finished after repair.")
+	}
+
+	t.Log("Repair an intact installation.")
+	calls = nil
+	if err := Repair(s); err != nil || len(calls) != 0 {
+		t.Error("Intact installation should not be re-applied.")
+	}
+}
+
+func TestRepairFailure(t *testing.T) {
+	errDo := errors.New("do")
+	var test = []struct {
+		policy ErrorPolicy
+		calls  []string
+	}{
+		{AbortOnError, []string{"do a"}},
+		{ContinueOnError, []string{"do a", "do b"}},
+	}
+	for _, tt := range test {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", errDo, nil),
+			recordStep(&calls, "b", nil, nil),
+		})
+		s.SetPolicy(tt.policy)
+
+		t.Log("Repair a not executed installation.")
+		if err := s.Repair(); err != errDo {
+			t.Errorf("Repair should fail with %v.", errDo)
+		}
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("Repair should apply %v, got %v.", tt.calls, calls)
+		}
+	}
+}
+
+func TestRepairDrift(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink("target", filepath.Join(dir, "link")); err != nil {
+		t.Skip("Symbolic link is not supported.")
+	}
+	file := func(path string) Stepper { return NewFileStep(path, []byte("a"), 0644) }
+	directory := func(path string) Stepper { return NewDirStep(path, 0755) }
+	link := func(path string) Stepper { return NewSymlinkStep("target", path) }
+	var test = []struct {
+		name    string
+		stepper func(path string) Stepper
+		drift   func(path string)
+	}{
+		{"FileMissing", file, func(path string) { os.Remove(path) }},
+		{"FileChanged", file, func(path string) { os.WriteFile(path, []byte("b"), 0644) }},
+		{"DirMissing", directory, func(path string) { os.Remove(path) }},
+		{"DirType", directory, func(path string) { os.Remove(path); os.WriteFile(path, nil, 0644) }},
+		{"SymlinkMissing", link, func(path string) { os.Remove(path) }},
+		{"SymlinkRetargeted", link, func(path string) { os.Remove(path); os.Symlink("other", path) }},
+		{"SymlinkType", link, func(path string) { os.Remove(path); os.WriteFile(path, nil, 0644) }},
+	}
+
+	t.Log("Repair each kind of drift.")
+	for _, tt := range test {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			s := tt.stepper(path)
+			if err := s.Do(); err != nil {
+				t.Fatal("Stepper should be able to do.")
+			}
+			tt.drift(path)
+			if err := Verify(s); err == nil {
+				t.Fatal("Stepper should be drifted.")
+			}
+			if err := Repair(s); err != nil {
+				t.Fatalf("Stepper should be able to repair, got %v.", err)
+			}
+			if err := Verify(s); err != nil {
+				t.Errorf("Stepper should be intact after repair, got %v.", err)
+			}
+		})
+	}
+}
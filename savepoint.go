@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"sync/atomic"
+)
+
+// AddSavepoint marks a savepoint named name after the first index steppers.
+func (s *Steps) AddSavepoint(name string, index int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if index < 0 || index > len(s.steppers) {
+		return ErrStepsSavepointRange
+	}
+	if s.savepoints == nil {
+		s.savepoints = map[string]int{}
+	}
+	s.savepoints[name] = index
+	return nil
+}
+
+// RollbackTo reverts the steppers executed after the savepoint named name.
+// The steps can be resumed from the savepoint afterwards.
+func (s *Steps) RollbackTo(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index, ok := s.savepoints[name]
+	if !ok {
+		return ErrStepsNoSavepoint
+	}
+	if s.step == 0 {
+		return ErrStepsNotExecuted
+	}
+	if index > s.Step() {
+		return ErrStepsSavepointNotReached
+	}
+	errs := errorList{}.append(s.Error())
+	err := s.revert(index, s.Step())
+	s.err = errs.append(err).err()
+	s.step = index * s.action
+	s.savepoint = name
+	return err
+}
+
+// Resume continues the action from the current stepper if it failed, otherwise the next one.
+func (s *Steps) Resume() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.checkSteppers(); err != nil {
+		return err
+	}
+	if s.action == 0 {
+		return ErrStepsNotExecuted
+	}
+	start := s.Step()
+	if start > 0 && !s.done[start-1] {
+		start--
+	}
+	if start == len(s.steppers) {
+		return ErrStepsExecuted
+	}
+	for _, ss := range s.steppers[start:] {
+		ss.Reset()
+	}
+	atomic.StoreInt32(&s.interrupted, 0)
+	return s.run(s.action, start)
+}
+
+// Savepoint return the name of savepoint the steps was last rolled back to.
+func (s *Steps) Savepoint() string {
+	return s.savepoint
+}
+
+// lastSavepoint return the last savepoint not after the index-th stepper.
+// The beginning of steps is returned if there is no such savepoint.
+func (s *Steps) lastSavepoint(index int) (string, int) {
+	name, last := "", -1
+	for n, i := range s.savepoints {
+		if i <= index && (i > last || i == last && n < name) {
+			name, last = n, i
+		}
+	}
+	if last < 0 {
+		return "", 0
+	}
+	return name, last
+}
+
+// revert resets the steppers from from to to, and triggers the opposite action
+// of executed ones in reverse order.
+// A failed composite stepper reverts its own executed part.
+func (s *Steps) revert(from int, to int) error {
+	var errs errorList
+	for i := to - 1; i >= from; i-- {
+		if !s.done[i] {
+			if p, ok := s.steppers[i].(partial); ok {
+				errs = errs.append(p.revertPartial())
+			}
+			s.steppers[i].Reset()
+			continue
+		}
+		s.steppers[i].Reset()
+		s.done[i] = false
+		errs = errs.append(execute(s.steppers[i], -s.action))
+	}
+	return errs.err()
+}
+
+// partial implements composite steppers reverting the executed part of a failed action.
+type partial interface {
+	revertPartial() error
+}
+
+func (s *Steps) revertPartial() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.done == nil {
+		return nil
+	}
+	return s.revert(0, s.Step())
+}
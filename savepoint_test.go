@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStepsAddSavepoint(t *testing.T) {
+	t.Log("Add savepoints to a steps.")
+	var test = []struct {
+		index  int
+		result error
+	}{
+		{
+			index:  0,
+			result: nil,
+		},
+		{
+			index:  2,
+			result: nil,
+		},
+		{
+			index:  -1,
+			result: ErrStepsSavepointRange,
+		},
+		{
+			index:  3,
+			result: ErrStepsSavepointRange,
+		},
+	}
+	for _, tt := range test {
+		t.Run("Normal", func(t *testing.T) {
+			s := NewSteps([]Stepper{nil, nil})
+			if err := s.AddSavepoint("save", tt.index); err != tt.result {
+				t.Error("Savepoint should be checked.")
+			}
+		})
+	}
+}
+
+func TestStepsSavepointRollback(t *testing.T) {
+	errDo := errors.New("do")
+
+	t.Log("Rollback to the last savepoint on failure.")
+	t.Run("Normal", func(t *testing.T) {
+		var calls []string
+		fail := errDo
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			recordStep(&calls, "b", nil, nil),
+			recordStep(&calls, "c", nil, nil),
+			NewStep(
+				func() error {
+					calls = append(calls, "do d")
+					return fail
+				},
+				nil,
+			),
+		}).SetPolicy(RollbackOnError)
+		s.AddSavepoint("first", 1)
+		s.AddSavepoint("none", 4)
+		if err := s.Do(); !errors.Is(err, errDo) {
+			t.Error("Steps should return the error of failed stepper.")
+		}
+		if want := []string{"do a", "do b", "do c", "do d", "undo c", "undo b"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+		if s.Savepoint() != "first" || s.Step() != 1 || s.Action() != 1 {
+			t.Error("Steps should be at the savepoint.")
+		}
+		if err := s.Error(); !errors.Is(err, errDo) {
+			t.Error("Error of failed stepper should be able to get.")
+		}
+
+		t.Log("Resume from the savepoint.")
+		calls = nil
+		fail = nil
+		if err := s.Resume(); err != nil {
+			t.Error("Steps should be able to resume.")
+		}
+		if want := []string{"do b", "do c", "do d"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+		if !s.Fin() || s.Error() != nil {
+			t.Error("Steps should be finished.")
+		}
+		if err := s.Resume(); err != ErrStepsExecuted {
+			t.Error("Finished steps should not be able to resume.")
+		}
+	})
+
+	t.Log("Rollback to a named savepoint.")
+	t.Run("Named", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			recordStep(&calls, "b", nil, nil),
+			recordStep(&calls, "c", nil, nil),
+		})
+		s.AddSavepoint("second", 2)
+		if err := s.RollbackTo("second"); err != ErrStepsNotExecuted {
+			t.Error("Non-executed steps should not be able to rollback.")
+		}
+		s.Do()
+		calls = nil
+		if err := s.RollbackTo("first"); err != ErrStepsNoSavepoint {
+			t.Error("Steps should not rollback to unknown savepoint.")
+		}
+		if err := s.RollbackTo("second"); err != nil {
+			t.Error("Steps should be able to rollback.")
+		}
+		if want := []string{"undo c"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+		if s.Step() != 2 || s.Fin() {
+			t.Error("Steps should be at the savepoint.")
+		}
+	})
+
+	t.Log("Resume an aborted steps.")
+	t.Run("Abort", func(t *testing.T) {
+		var calls []string
+		fail := errDo
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			NewStep(
+				func() error {
+					calls = append(calls, "do b")
+					return fail
+				},
+				nil,
+			),
+		})
+		if err := s.Resume(); err != ErrStepsNotExecuted {
+			t.Error("Non-executed steps should not be able to resume.")
+		}
+		s.Do()
+		fail = nil
+		calls = nil
+		if err := s.Resume(); err != nil {
+			t.Error("Steps should be able to resume.")
+		}
+		if want := []string{"do b"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+	})
+}
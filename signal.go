@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// Interrupter implements the interruption of a running stepper.
+type Interrupter interface {
+	Interrupt()
+}
+
+// exit terminates the process, and is replaced in tests.
+var exit = os.Exit
+
+// RunWithSignals triggers the doer of stepper while listening to SIGINT and SIGTERM,
+// or to the interrupt note on plan9.
+// The first signal interrupts the stepper after its current stepper, so that the
+// configured policy, like rollback, is applied.
+// The second signal calls flush, if any, and exits immediately.
+func RunWithSignals(stepper Stepper, flush func()) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, terminations...)
+	defer signal.Stop(signals)
+	return runWithSignals(stepper, flush, signals)
+}
+
+func runWithSignals(stepper Stepper, flush func(), signals <-chan os.Signal) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		if i, ok := stepper.(Interrupter); ok {
+			i.Interrupt()
+		}
+		select {
+		case sig := <-signals:
+			if flush != nil {
+				flush()
+			}
+			exit(exitCode(sig))
+		case <-done:
+		}
+	}()
+	return stepper.Do()
+}
+
+// Interrupt stops the steps before its next stepper and interrupts the running one.
+func (s *Steps) Interrupt() {
+	atomic.StoreInt32(&s.interrupted, 1)
+	interrupt(s.steppers)
+}
+
+// Interrupt interrupts the running steppers.
+func (p *Parallel) Interrupt() {
+	interrupt(p.steppers)
+}
+
+// Interrupt interrupts the stepper.
+func (c *Conditional) Interrupt() {
+	interrupt([]Stepper{c.stepper})
+}
+
+// Interrupt interrupts the stepper.
+func (o *Optional) Interrupt() {
+	interrupt([]Stepper{o.Stepper})
+}
+
+func interrupt(steppers []Stepper) {
+	for _, ss := range steppers {
+		if i, ok := ss.(Interrupter); ok {
+			i.Interrupt()
+		}
+	}
+}
+
+func (s *Steps) isInterrupted() bool {
+	return atomic.LoadInt32(&s.interrupted) != 0
+}
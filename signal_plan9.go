@@ -0,0 +1,16 @@
+//go:build plan9
+// +build plan9
+
+package installer
+
+import (
+	"os"
+)
+
+// terminations are the notes listened by RunWithSignals.
+var terminations = []os.Signal{os.Interrupt}
+
+// exitCode return the exit code of process terminated by sig, which has no convention on plan9.
+func exitCode(sig os.Signal) int {
+	return 1
+}
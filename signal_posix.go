@@ -0,0 +1,20 @@
+//go:build !plan9
+// +build !plan9
+
+package installer
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminations are the signals listened by RunWithSignals.
+var terminations = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// exitCode return the conventional exit code of process terminated by sig.
+func exitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,16 @@
+//go:build !plan9
+// +build !plan9
+
+package installer
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Log("Get exit code of signal.")
+	if exitCode(syscall.SIGINT) != 130 {
+		t.Error("Exit code should follow the convention.")
+	}
+}
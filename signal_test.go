@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRunWithSignals(t *testing.T) {
+	t.Log("Run a steps without signal.")
+	t.Run("Normal", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+		})
+		if err := runWithSignals(s, nil, make(chan os.Signal)); err != nil {
+			t.Error("Steps should be able to do.")
+		}
+	})
+
+	t.Log("Interrupt a steps by a signal.")
+	t.Run("Interrupt", func(t *testing.T) {
+		var calls []string
+		signals := make(chan os.Signal, 2)
+		interrupting := true
+		var inner *Steps
+		inner = NewSteps([]Stepper{
+			NewStep(
+				func() error {
+					calls = append(calls, "do b")
+					if interrupting {
+						signals <- os.Interrupt
+						for !inner.isInterrupted() {
+						}
+					}
+					return nil
+				},
+				func() error {
+					calls = append(calls, "undo b")
+					return nil
+				},
+			),
+			recordStep(&calls, "c", nil, nil),
+		})
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			inner,
+			recordStep(&calls, "d", nil, nil),
+		}).SetPolicy(RollbackOnError)
+		if err := runWithSignals(s, nil, signals); !errors.Is(err, ErrStepsInterrupted) {
+			t.Error("Steps should be interrupted.")
+		}
+		if want := []string{"do a", "do b", "undo b", "undo a"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+		if err := s.Error(); !errors.Is(err, ErrStepsInterrupted) {
+			t.Error("Interruption should be able to get.")
+		}
+
+		t.Log("Resume an interrupted steps.")
+		calls = nil
+		interrupting = false
+		if err := s.Resume(); err != nil {
+			t.Error("Steps should be able to resume.")
+		}
+		if want := []string{"do a", "do b", "do c", "do d"}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("Steps should call %v, got %v.", want, calls)
+		}
+	})
+
+	t.Log("Exit by the second signal.")
+	t.Run("Exit", func(t *testing.T) {
+		defer func() { exit = os.Exit }()
+		exited := make(chan int, 1)
+		exit = func(code int) { exited <- code }
+		flushed := false
+		signals := make(chan os.Signal, 2)
+		s := NewSteps([]Stepper{
+			NewStep(
+				func() error {
+					signals <- os.Interrupt
+					signals <- os.Interrupt
+					<-exited
+					return nil
+				},
+				nil,
+			),
+		})
+		runWithSignals(s, func() { flushed = true }, signals)
+		if !flushed {
+			t.Error("Flush should be called before exit.")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Slots is the step installing versions into two slots "<live>.a" and "<live>.b",
+// with live as a symbolic link to the active one.
+//
+// A new version is installed into the inactive slot, live is flipped to it,
+// and the health checks run. If any of them fails, live is flipped back
+// and the steps of the new slot are undone.
+type Slots struct {
+	*baseStep
+	live    string
+	install func(dir string) Stepper
+	checks  []func() error
+
+	stepper Stepper
+}
+
+// NewSlots creates step installing into the inactive slot of live by the stepper from install,
+// and checking health by checks after flipping.
+// Undo flips live back to the other slot and undoes the steps of the active one.
+func NewSlots(live string, install func(dir string) Stepper, checks ...func() error) *Slots {
+	if abs, err := filepath.Abs(live); err == nil {
+		live = abs
+	}
+	s := &Slots{
+		live:    filepath.Clean(live),
+		install: install,
+		checks:  checks,
+	}
+	var do func() error
+	if install != nil {
+		do = s.upgrade
+	}
+	s.baseStep = NewStep(do, s.downgrade)
+	return s
+}
+
+// Active return the absolute path of active slot, or empty if live does not exist.
+func (s *Slots) Active() string {
+	target, err := os.Readlink(s.live)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		return filepath.Join(filepath.Dir(s.live), target)
+	}
+	return filepath.Clean(target)
+}
+
+// Inactive return the absolute path of slot a new version is installed into.
+func (s *Slots) Inactive() string {
+	if s.Active() == s.live+".a" {
+		return s.live + ".b"
+	}
+	return s.live + ".a"
+}
+
+func (s *Slots) upgrade() error {
+	active, slot := s.Active(), s.Inactive()
+	if err := os.RemoveAll(slot); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(slot, 0755); err != nil {
+		return err
+	}
+	s.stepper = s.install(slot)
+	if err := s.stepper.Do(); err != nil {
+		return errorList{}.append(err).append(s.discard(slot)).err()
+	}
+	if err := replaceLink(s.live, filepath.Base(slot)); err != nil {
+		return errorList{}.append(err).append(s.discard(slot)).err()
+	}
+	for _, check := range s.checks {
+		if err := call(check); err != nil {
+			return errorList{}.
+				append(fmt.Errorf("%s: %w", slot, err)).
+				append(s.flip(active)).
+				append(s.discard(slot)).
+				err()
+		}
+	}
+	return nil
+}
+
+func (s *Slots) downgrade() error {
+	active := s.Active()
+	if active == "" {
+		return nil
+	}
+	other := s.live + ".a"
+	if active == other {
+		other = s.live + ".b"
+	}
+	if _, err := os.Stat(other); os.IsNotExist(err) {
+		other = ""
+	}
+	if s.stepper == nil {
+		s.stepper = s.install(active)
+	}
+	if err := s.flip(other); err != nil {
+		return err
+	}
+	return s.discard(active)
+}
+
+// flip points live to slot, or removes live if slot is empty.
+func (s *Slots) flip(slot string) error {
+	if slot == "" {
+		return removeIfExist(s.live)
+	}
+	return replaceLink(s.live, filepath.Base(slot))
+}
+
+// discard undoes the steps of slot and removes it.
+func (s *Slots) discard(slot string) error {
+	var errs errorList
+	if s.stepper != nil {
+		s.stepper.Reset()
+		errs = errs.append(s.stepper.Undo())
+		s.stepper = nil
+	}
+	return errs.append(os.RemoveAll(slot)).err()
+}
@@ -0,0 +1,133 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSlots(t *testing.T) {
+	errUnhealthy := errors.New("unhealthy")
+	live := filepath.Join(t.TempDir(), "app")
+	var calls []string
+	healthy := true
+	install := func(content string) func(string) Stepper {
+		return func(dir string) Stepper {
+			return NewSteps([]Stepper{
+				recordStep(&calls, content, nil, nil),
+				NewFileStep(filepath.Join(dir, "file"), []byte(content), 0644),
+			})
+		}
+	}
+	check := func() error {
+		if !healthy {
+			return errUnhealthy
+		}
+		return nil
+	}
+
+	t.Log("Install the first version into a slot.")
+	s := NewSlots(live, install("v1"), check)
+	if err := s.Do(); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			t.Skip("Symbolic link is not supported.")
+		}
+		t.Fatalf("Slots should be able to do, got %v.", err)
+	}
+	if s.Active() != live+".a" || readStaged(live) != "v1" {
+		t.Fatal("First version should be live in slot a.")
+	}
+
+	t.Log("Install the second version into the other slot.")
+	s = NewSlots(live, install("v2"), check)
+	if err := s.Do(); err != nil {
+		t.Fatal("Slots should be able to do.")
+	}
+	if s.Active() != live+".b" || readStaged(live) != "v2" {
+		t.Fatal("Second version should be live in slot b.")
+	}
+
+	t.Log("Flip back on an unhealthy version.")
+	calls = nil
+	healthy = false
+	s = NewSlots(live, install("v3"), check)
+	if err := s.Do(); !errors.Is(err, errUnhealthy) {
+		t.Fatalf("Slots should fail with %v, got %v.", errUnhealthy, err)
+	}
+	if s.Active() != live+".b" || readStaged(live) != "v2" {
+		t.Error("Second version should still be live.")
+	}
+	if !reflect.DeepEqual(calls, []string{"do v3", "undo v3"}) {
+		t.Errorf("Steps of the new slot should be undone, got %v.", calls)
+	}
+	if _, err := os.Stat(live + ".a"); !os.IsNotExist(err) {
+		t.Error("New slot should be removed.")
+	}
+
+	t.Log("Install into the inactive slot of an unclean live path.")
+	healthy = true
+	s = NewSlots(filepath.Dir(live)+string(filepath.Separator)+"."+string(filepath.Separator)+"app", install("v4"), check)
+	if s.Inactive() != live+".a" {
+		t.Fatalf("Inactive slot should be a, got %q.", s.Inactive())
+	}
+	if err := s.Do(); err != nil {
+		t.Fatal("Slots should be able to do.")
+	}
+	if s.Active() != live+".a" || readStaged(live) != "v4" || readStaged(live+".b") != "v2" {
+		t.Fatal("New version should be live in slot a with slot b kept.")
+	}
+	s.Reset()
+	if err := s.Undo(); err != nil || readStaged(live) != "v2" {
+		t.Fatal("Slots should be able to undo to slot b.")
+	}
+
+	t.Log("Undo to the previous slot.")
+	healthy = true
+	s = NewSlots(live, install("v2"), check)
+	if err := s.Undo(); err != nil {
+		t.Fatal("Slots should be able to undo.")
+	}
+	if _, err := os.Lstat(live); !os.IsNotExist(err) {
+		t.Error("Live should be removed without another slot.")
+	}
+}
+
+func TestSlotsAbsoluteTarget(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Working directory should be known.")
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal("Working directory should be able to change.")
+	}
+	defer os.Chdir(wd)
+	os.MkdirAll(filepath.Join(dir, "app.a"), 0755)
+	os.WriteFile(filepath.Join(dir, "app.a", "file"), []byte("v1"), 0644)
+	if err := os.Symlink(filepath.Join(dir, "app.a"), "app"); err != nil {
+		t.Skip("Symbolic link is not supported.")
+	}
+	install := func(dir string) Stepper {
+		return NewFileStep(filepath.Join(dir, "file"), []byte("v2"), 0644)
+	}
+
+	t.Log("Install beside a slot linked by an absolute path from a relative live path.")
+	s := NewSlots("app", install)
+	if s.Inactive() != filepath.Join(dir, "app.b") {
+		t.Fatalf("Inactive slot should be b, got %q.", s.Inactive())
+	}
+	if err := s.Do(); err != nil {
+		t.Fatalf("Slots should be able to do, got %v.", err)
+	}
+	if readStaged("app") != "v2" || readStaged("app.a") != "v1" {
+		t.Fatal("New version should be live in slot b with slot a kept.")
+	}
+
+	t.Log("Undo to the slot linked by the absolute path.")
+	s.Reset()
+	if err := s.Undo(); err != nil || readStaged("app") != "v1" {
+		t.Fatal("Slots should be able to undo to slot a.")
+	}
+}
@@ -0,0 +1,217 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Staged is the step staging a whole tree aside and swapping it into place,
+// so that the live tree is never half-written.
+//
+// The payload is staged into "<live>.staging". If live is a directory,
+// it is renamed to "<live>.previous" and the staged tree is renamed to live.
+// If live is a symbolic link, the staged tree is moved to a versioned sibling,
+// live is atomically replaced by a link to it, and "<live>.previous" links to the old target.
+type Staged struct {
+	*baseStep
+	live  string
+	stage func(dir string) error
+}
+
+// NewStaged creates step staging the tree of live by stage, which writes the payload into dir.
+// Undo restores the previous version, or removes live if there is none.
+func NewStaged(live string, stage func(dir string) error) *Staged {
+	s := &Staged{
+		live:  live,
+		stage: stage,
+	}
+	var do func() error
+	if stage != nil {
+		do = s.install
+	}
+	s.baseStep = NewStep(do, s.restore)
+	return s
+}
+
+// Live return the path of live tree.
+func (s *Staged) Live() string {
+	return s.live
+}
+
+// Previous return the path of previous version.
+func (s *Staged) Previous() string {
+	return s.live + ".previous"
+}
+
+func (s *Staged) install() error {
+	staging := s.live + ".staging"
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return err
+	}
+	if err := call(func() error { return s.stage(staging) }); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	info, err := os.Lstat(s.live)
+	switch {
+	case os.IsNotExist(err):
+		err = os.Rename(staging, s.live)
+	case err != nil:
+	case info.Mode()&os.ModeSymlink != 0:
+		err = s.swapLink(staging)
+	default:
+		err = s.swapDir(staging)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+	}
+	return err
+}
+
+func (s *Staged) swapDir(staging string) error {
+	if err := s.removePrevious(); err != nil {
+		return err
+	}
+	if err := os.Rename(s.live, s.Previous()); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, s.live); err != nil {
+		os.Rename(s.Previous(), s.live)
+		return err
+	}
+	return nil
+}
+
+func (s *Staged) swapLink(staging string) error {
+	old, err := os.Readlink(s.live)
+	if err != nil {
+		return err
+	}
+	target := fmt.Sprintf("%s.%d", s.live, time.Now().UnixNano())
+	if err := os.Rename(staging, target); err != nil {
+		return err
+	}
+	// The new link follows the old one, absolute or relative to live.
+	link := filepath.Base(target)
+	if filepath.IsAbs(old) {
+		if link, err = filepath.Abs(target); err != nil {
+			os.RemoveAll(target)
+			return err
+		}
+	}
+	if err := s.removePrevious(); err != nil {
+		os.RemoveAll(target)
+		return err
+	}
+	if err := os.Symlink(old, s.Previous()); err != nil {
+		os.RemoveAll(target)
+		return err
+	}
+	if err := replaceLink(s.live, link); err != nil {
+		os.RemoveAll(target)
+		os.Remove(s.Previous())
+		return err
+	}
+	return nil
+}
+
+// removePrevious removes the previous version, including the target of a previous link.
+func (s *Staged) removePrevious() error {
+	info, err := os.Lstat(s.Previous())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		previous, err := os.Readlink(s.Previous())
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(s.resolve(previous)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(s.Previous())
+}
+
+func (s *Staged) restore() error {
+	info, err := os.Lstat(s.Previous())
+	if os.IsNotExist(err) {
+		return s.removeLive()
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		if err := os.RemoveAll(s.live); err != nil {
+			return err
+		}
+		return os.Rename(s.Previous(), s.live)
+	}
+
+	previous, err := os.Readlink(s.Previous())
+	if err != nil {
+		return err
+	}
+	current, err := os.Readlink(s.live)
+	if err != nil {
+		return err
+	}
+	if err := replaceLink(s.live, previous); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.resolve(current)); err != nil {
+		return err
+	}
+	return os.Remove(s.Previous())
+}
+
+// removeLive removes the live tree, including the target of a live link.
+func (s *Staged) removeLive() error {
+	info, err := os.Lstat(s.live)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		current, err := os.Readlink(s.live)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(s.resolve(current)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(s.live)
+}
+
+// resolve return the path of link target relative to live.
+func (s *Staged) resolve(target string) string {
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(filepath.Dir(s.live), target)
+}
+
+// replaceLink atomically replaces the symbolic link at path by a link to target.
+func replaceLink(path, target string) error {
+	tmp := path + ".tmp"
+	if err := removeIfExist(tmp); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func stageFile(content string) func(string) error {
+	return func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, "file"), []byte(content), 0644)
+	}
+}
+
+func readStaged(live string) string {
+	b, err := os.ReadFile(filepath.Join(live, "file"))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func TestStagedDir(t *testing.T) {
+	live := filepath.Join(t.TempDir(), "app")
+
+	t.Log("Stage a fresh install.")
+	s := NewStaged(live, stageFile("v1"))
+	if err := s.Do(); err != nil {
+		t.Fatal("Staged should be able to do.")
+	}
+	if readStaged(live) != "v1" {
+		t.Error("Staged tree should be live.")
+	}
+
+	t.Log("Stage an update.")
+	s = NewStaged(live, stageFile("v2"))
+	if err := s.Do(); err != nil {
+		t.Fatal("Staged should be able to do.")
+	}
+	if readStaged(live) != "v2" || readStaged(s.Previous()) != "v1" {
+		t.Error("Staged tree should be live with the previous version kept.")
+	}
+	if _, err := os.Stat(live + ".staging"); !os.IsNotExist(err) {
+		t.Error("Staging directory should be moved.")
+	}
+
+	t.Log("Restore the previous version.")
+	s.Reset()
+	if err := s.Undo(); err != nil {
+		t.Fatal("Staged should be able to undo.")
+	}
+	if readStaged(live) != "v1" {
+		t.Error("Previous version should be live.")
+	}
+
+	t.Log("Remove the only version.")
+	s.Reset()
+	if err := s.Undo(); err != nil {
+		t.Fatal("Staged should be able to undo.")
+	}
+	if _, err := os.Stat(live); !os.IsNotExist(err) {
+		t.Error("Live tree should be removed.")
+	}
+}
+
+func TestStagedLink(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "app")
+	os.Mkdir(filepath.Join(dir, "v1"), 0755)
+	os.WriteFile(filepath.Join(dir, "v1", "file"), []byte("v1"), 0644)
+	if err := os.Symlink("v1", live); err != nil {
+		t.Skip("Symbolic link is not supported.")
+	}
+
+	t.Log("Stage an update behind a link.")
+	s := NewStaged(live, stageFile("v2"))
+	if err := s.Do(); err != nil {
+		t.Fatalf("Staged should be able to do, got %v.", err)
+	}
+	if info, err := os.Lstat(live); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("Live should still be a link.")
+	}
+	if readStaged(live) != "v2" || readStaged(s.Previous()) != "v1" {
+		t.Error("Link should point to the staged tree with the previous version kept.")
+	}
+
+	t.Log("Restore the previous version behind a link.")
+	current, _ := os.Readlink(live)
+	s.Reset()
+	if err := s.Undo(); err != nil {
+		t.Fatalf("Staged should be able to undo, got %v.", err)
+	}
+	if target, _ := os.Readlink(live); target != "v1" {
+		t.Errorf("Link should point to the previous target, got %q.", target)
+	}
+	if _, err := os.Stat(filepath.Join(dir, current)); !os.IsNotExist(err) {
+		t.Error("Staged tree should be removed.")
+	}
+}
+
+func TestStagedLinkRelativeLive(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal("Working directory should be able to change.")
+	}
+	defer os.Chdir(wd)
+	os.MkdirAll(filepath.Join(dir, "sub", "v1"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "v1", "file"), []byte("v1"), 0644)
+	live := filepath.Join("sub", "app")
+	if err := os.Symlink(filepath.Join(dir, "sub", "v1"), live); err != nil {
+		t.Skip("Symbolic link is not supported.")
+	}
+
+	t.Log("Stage an update behind an absolute link at a relative live path.")
+	s := NewStaged(live, stageFile("v2"))
+	if err := s.Do(); err != nil {
+		t.Fatalf("Staged should be able to do, got %v.", err)
+	}
+	if target, _ := os.Readlink(live); !filepath.IsAbs(target) {
+		t.Errorf("Link should stay absolute, got %q.", target)
+	}
+	if readStaged(live) != "v2" {
+		t.Error("Link should point to the staged tree.")
+	}
+}
+
+func TestStagedFailure(t *testing.T) {
+	errStage := errors.New("stage")
+	live := filepath.Join(t.TempDir(), "app")
+	NewStaged(live, stageFile("v1")).Do()
+
+	t.Log("Fail to stage an update.")
+	s := NewStaged(live, func(dir string) error {
+		stageFile("half")(dir)
+		return errStage
+	})
+	if err := s.Do(); err != errStage {
+		t.Fatal("Staged should fail.")
+	}
+	if readStaged(live) != "v1" {
+		t.Error("Live tree should be untouched.")
+	}
+	if _, err := os.Stat(live + ".staging"); !os.IsNotExist(err) {
+		t.Error("Staging directory should be removed.")
+	}
+}
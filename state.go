@@ -0,0 +1,243 @@
+package installer
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Stateful implements steppers sharing the state of a run.
+type Stateful interface {
+	SetState(state *State)
+}
+
+// State is the concurrency-safe key/value store shared by steppers in a run.
+// Values of secret keys are not marshaled.
+type State struct {
+	mutex   *sync.RWMutex
+	values  map[string]interface{}
+	secrets map[string]bool
+}
+
+type stateJSON struct {
+	Values  map[string]json.RawMessage `json:"values"`
+	Secrets []string                   `json:"secrets,omitempty"`
+}
+
+// NewState creates an empty state.
+func NewState() *State {
+	return &State{
+		mutex:   &sync.RWMutex{},
+		values:  map[string]interface{}{},
+		secrets: map[string]bool{},
+	}
+}
+
+// Set sets the value of key.
+func (s *State) Set(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+	delete(s.secrets, key)
+}
+
+// SetSecret sets the value of key, which is redacted on marshaling.
+func (s *State) SetSecret(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+	s.secrets[key] = true
+}
+
+// Get return the value of key.
+// Values restored by unmarshaling are json.RawMessage until read by Lookup.
+func (s *State) Get(key string) (interface{}, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Delete removes key.
+func (s *State) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.values, key)
+	delete(s.secrets, key)
+}
+
+// Keys return the sorted keys with value.
+func (s *State) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Secret reports whether key is secret.
+func (s *State) Secret(key string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.secrets[key]
+}
+
+// MarshalJSON marshals the values except secrets, and the keys of secrets.
+func (s *State) MarshalJSON() ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	j := stateJSON{
+		Values: map[string]json.RawMessage{},
+	}
+	for key, value := range s.values {
+		if s.secrets[key] {
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		j.Values[key] = b
+	}
+	for key := range s.secrets {
+		j.Secrets = append(j.Secrets, key)
+	}
+	sort.Strings(j.Secrets)
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON restores the values marshaled by MarshalJSON.
+// Secrets are restored as keys without value.
+func (s *State) UnmarshalJSON(b []byte) error {
+	var j stateJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if s.mutex == nil {
+		*s = *NewState()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, value := range j.Values {
+		s.values[key] = value
+	}
+	for _, key := range j.Secrets {
+		s.secrets[key] = true
+	}
+	return nil
+}
+
+// Lookup return the value of key as T.
+// Values restored by unmarshaling are decoded into T.
+func Lookup[T any](state *State, key string) (T, bool) {
+	var zero T
+	value, ok := state.Get(key)
+	if !ok {
+		return zero, false
+	}
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		return zero, false
+	}
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return zero, false
+	}
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.values[key] = v
+	return v, true
+}
+
+// NewStepWithState creates step with doer and undoer receiving the state of run.
+func NewStepWithState(doer func(*State) error, undoer func(*State) error) *Step {
+	s := NewStep(nil, nil)
+	if doer != nil {
+		s.doer = func() error { return doer(s.state) }
+	}
+	if undoer != nil {
+		s.undoer = func() error { return undoer(s.state) }
+	}
+	return s
+}
+
+// SetState sets the state of run.
+func (s *Step) SetState(state *State) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state = state
+}
+
+// SetState sets the state of run to steps and all steppers.
+func (s *Steps) SetState(state *State) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.setState(state)
+}
+
+// State return the state of run.
+func (s *Steps) State() *State {
+	return s.state
+}
+
+func (s *Steps) setState(state *State) {
+	s.state = state
+	setState(s.steppers, state)
+}
+
+// ensureState creates a state for the run if there is none.
+func (s *Steps) ensureState() {
+	if s.state == nil {
+		s.setState(NewState())
+	}
+}
+
+// SetState sets the state of run to parallel and all steppers.
+func (p *Parallel) SetState(state *State) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.setState(state)
+}
+
+// State return the state of run.
+func (p *Parallel) State() *State {
+	return p.state
+}
+
+func (p *Parallel) setState(state *State) {
+	p.state = state
+	setState(p.steppers, state)
+}
+
+// SetState sets the state of run to the stepper.
+func (c *Conditional) SetState(state *State) {
+	setState([]Stepper{c.stepper}, state)
+}
+
+// SetState sets the state of run to the stepper.
+func (o *Optional) SetState(state *State) {
+	setState([]Stepper{o.Stepper}, state)
+}
+
+// SetState sets the state of run to the tree.
+func (c *Chaos) SetState(state *State) {
+	setState([]Stepper{c.Stepper}, state)
+}
+
+func (f *fault) SetState(state *State) {
+	setState([]Stepper{f.Stepper}, state)
+}
+
+func setState(steppers []Stepper, state *State) {
+	for _, ss := range steppers {
+		if s, ok := ss.(Stateful); ok {
+			s.SetState(state)
+		}
+	}
+}
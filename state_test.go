@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	t.Log("Set and get values.")
+	s := NewState()
+	s.Set("dir", "/tmp/a")
+	s.SetSecret("password", "secret")
+	s.Set("port", 8080)
+	if v, ok := Lookup[string](s, "dir"); !ok || v != "/tmp/a" {
+		t.Error("Value should be able to get.")
+	}
+	if _, ok := Lookup[int](s, "dir"); ok {
+		t.Error("Value should not be able to get as another type.")
+	}
+	if _, ok := Lookup[string](s, "none"); ok {
+		t.Error("Missing value should not be able to get.")
+	}
+	if !s.Secret("password") || s.Secret("dir") {
+		t.Error("Secret should be recorded.")
+	}
+	if keys := s.Keys(); !reflect.DeepEqual(keys, []string{"dir", "password", "port"}) {
+		t.Error("Keys should be sorted.")
+	}
+	s.Delete("port")
+	if _, ok := s.Get("port"); ok {
+		t.Error("Value should be deleted.")
+	}
+
+	t.Log("Marshal values with secrets redacted.")
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"values":{"dir":"/tmp/a"},"secrets":["password"]}` {
+		t.Errorf("Secrets should be redacted, got %s.", b)
+	}
+
+	t.Log("Unmarshal values.")
+	var r State
+	if err := json.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := Lookup[string](&r, "dir"); !ok || v != "/tmp/a" {
+		t.Error("Value should be restored.")
+	}
+	if _, ok := r.Get("password"); ok || !r.Secret("password") {
+		t.Error("Secret should be restored without value.")
+	}
+}
+
+func TestStateConcurrent(t *testing.T) {
+	t.Log("Set values concurrently.")
+	s := NewState()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Set(string(rune('a'+i)), i)
+			Lookup[int](s, "a")
+		}(i)
+	}
+	wg.Wait()
+	if len(s.Keys()) != 10 {
+		t.Error("All values should be set.")
+	}
+}
+
+func TestStepWithState(t *testing.T) {
+	t.Log("Share state between steps.")
+	var undone string
+	s := NewSteps([]Stepper{
+		NewStepWithState(
+			func(s *State) error {
+				s.Set("dir", "/tmp/a")
+				return nil
+			},
+			func(s *State) error {
+				undone, _ = Lookup[string](s, "dir")
+				return nil
+			},
+		),
+		NewParallel([]Stepper{
+			When(
+				func() (bool, string) { return true, "" },
+				NewStepWithState(
+					func(s *State) error {
+						if dir, _ := Lookup[string](s, "dir"); dir != "/tmp/a" {
+							t.Error("State should be shared.")
+						}
+						return errors.New("")
+					},
+					nil,
+				),
+			),
+		}),
+	}).SetPolicy(RollbackOnError)
+	s.Do()
+	if undone != "/tmp/a" {
+		t.Error("Undoer should get the state.")
+	}
+	if _, ok := s.State().Get("dir"); !ok {
+		t.Error("State should be kept after run.")
+	}
+
+	t.Log("Undo with a restored state.")
+	undone = ""
+	r := NewState()
+	r.Set("dir", "/tmp/b")
+	s.Reset()
+	s.SetState(r)
+	s.Undo()
+	if undone != "/tmp/b" {
+		t.Error("Undoer should get the restored state.")
+	}
+}
@@ -2,17 +2,21 @@ package installer
 
 import (
 	"math"
+	"runtime/debug"
 	"sync"
 )
 
 // Step is the basic component of a doer.
 type Step struct {
-	mutex *sync.Mutex
-	step  int
-	err   error
+	mutex  *sync.Mutex
+	step   int
+	err    error
+	weight float64
+	state  *State
 
-	doer   func() error
-	undoer func() error
+	doer       func() error
+	undoer     func() error
+	preflights []func() error
 }
 
 // NewStep creates step with doer and undoer.
@@ -25,6 +29,7 @@ func NewStep(doer func() error, undoer func() error) *Step {
 }
 
 // Do triggers the doer.
+// A panic in the doer is recovered as PanicError.
 func (s *Step) Do() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -34,7 +39,7 @@ func (s *Step) Do() error {
 	if s.step != 0 {
 		return ErrStepExecuted
 	}
-	s.err = s.doer()
+	s.err = call(s.doer)
 	s.step++
 	if s.err != nil {
 		return s.err
@@ -43,6 +48,7 @@ func (s *Step) Do() error {
 }
 
 // Undo triggers the undoer.
+// A panic in the undoer is recovered as PanicError.
 func (s *Step) Undo() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -52,7 +58,7 @@ func (s *Step) Undo() error {
 	if s.step != 0 {
 		return ErrStepExecuted
 	}
-	s.err = s.undoer()
+	s.err = call(s.undoer)
 	s.step--
 	if s.err != nil {
 		return s.err
@@ -100,3 +106,20 @@ func (s *Step) Reset() {
 	s.err = nil
 	s.step = 0
 }
+
+func (s *Step) base() *Step {
+	return s
+}
+
+// call triggers f and recovers a panic as PanicError.
+func call(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	return f()
+}
@@ -343,3 +343,57 @@ func TestStepError(t *testing.T) {
 		})
 	}
 }
+
+func TestStepPanic(t *testing.T) {
+	var test = []interface{}{
+		"panic",
+		errors.New("panic"),
+	}
+
+	t.Log("Recover a panic in doer.")
+	for _, tt := range test {
+		t.Run("Do", func(t *testing.T) {
+			v := tt
+			s := NewStep(func() error { panic(v) }, nil)
+			err := s.Do()
+			var perr *PanicError
+			if !errors.As(err, &perr) || perr.Value != v || len(perr.Stack) == 0 {
+				t.Fatal("Panic should be recovered as PanicError.")
+			}
+			if s.Error() != err || !s.Fin() {
+				t.Error("Step should be failed.")
+			}
+			if verr, ok := v.(error); ok && !errors.Is(err, verr) {
+				t.Error("PanicError should unwrap the panic error.")
+			}
+		})
+	}
+
+	t.Log("Recover a panic in undoer.")
+	for _, tt := range test {
+		t.Run("Undo", func(t *testing.T) {
+			v := tt
+			s := NewStep(nil, func() error { panic(v) })
+			var perr *PanicError
+			if err := s.Undo(); !errors.As(err, &perr) || perr.Value != v {
+				t.Error("Panic should be recovered as PanicError.")
+			}
+		})
+	}
+
+	t.Log("Rollback a steps with a panicked step.")
+	t.Run("Rollback", func(t *testing.T) {
+		var calls []string
+		s := NewSteps([]Stepper{
+			recordStep(&calls, "a", nil, nil),
+			NewStep(func() error { panic("panic") }, nil),
+		}).SetPolicy(RollbackOnError)
+		var perr *PanicError
+		if err := s.Do(); !errors.As(err, &perr) {
+			t.Error("Panic should be recovered as PanicError.")
+		}
+		if len(calls) != 2 || calls[1] != "undo a" {
+			t.Error("Steps should rollback.")
+		}
+	})
+}
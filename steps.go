@@ -3,9 +3,13 @@ package installer
 import (
 	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Stepper implements methods that would used by installer steps.
+// Do or Undo can only be triggered once until Reset, otherwise an executed error is returned.
+// Error return a not executed error before any action, and Progress is in range of 0~1.
 type Stepper interface {
 	Do() error
 	Undo() error
@@ -23,7 +27,21 @@ type Stepper interface {
 type Steps struct {
 	mutex    *sync.Mutex
 	step     int
+	err      error
+	action   int
+	done     []bool
+	policy   ErrorPolicy
 	steppers []Stepper
+
+	savepoint  string
+	savepoints map[string]int
+
+	started       time.Time
+	startProgress float64
+
+	interrupted int32
+
+	state *State
 }
 
 // NewSteps creates a set of steppers with given steppers.
@@ -41,16 +59,10 @@ func (s *Steps) Do() error {
 	if err := s.checkSteppers(); err != nil {
 		return err
 	}
-	if s.step != 0 {
+	if s.step != 0 || s.err != nil {
 		return ErrStepsExecuted
 	}
-	for _, ss := range s.steppers {
-		s.step++
-		if err := ss.Do(); err != nil {
-			return err
-		}
-	}
-	return nil
+	return s.run(1, 0)
 }
 
 // Undo triggers each steppers' undoer.
@@ -60,24 +72,23 @@ func (s *Steps) Undo() error {
 	if err := s.checkSteppers(); err != nil {
 		return err
 	}
-	if s.step != 0 {
+	if s.step != 0 || s.err != nil {
 		return ErrStepsExecuted
 	}
-	for _, ss := range s.steppers {
-		s.step--
-		if err := ss.Undo(); err != nil {
-			return err
-		}
-	}
-	return nil
+	return s.run(-1, 0)
 }
 
 // Error return the error during executing steppers.
+// Failures of previous steppers collected by the policy, including optional ones,
+// are reported together with the error of current stepper.
 func (s *Steps) Error() error {
 	if s.step == 0 {
+		if s.err != nil {
+			return s.err
+		}
 		return ErrStepsNotExecuted
 	}
-	return s.steppers[s.Step()-1].Error()
+	return errorList{}.append(s.err).append(s.steppers[s.Step()-1].Error()).err()
 }
 
 // Action return current action of steps.
@@ -100,12 +111,21 @@ func (s *Steps) Step() int {
 	return int(math.Abs(float64(s.step)))
 }
 
-// Progress return the progress status of steps.
+// Progress return the progress status of steps weighted by the steppers' weight.
 func (s *Steps) Progress() float64 {
 	if err := s.checkSteppers(); err != nil {
 		return 0
 	}
-	return math.Abs(float64(s.step)) / float64(len(s.steppers))
+	total := s.Weight()
+	if total <= 0 {
+		return 0
+	}
+	step := s.Step()
+	if step == 0 {
+		return 0
+	}
+	current := s.steppers[step-1]
+	return (weightSum(s.steppers[:step-1]) + weightOf(current)*progressOf(current)) / total
 }
 
 // Reset clears the status.
@@ -116,6 +136,110 @@ func (s *Steps) Reset() {
 		ss.Reset()
 	}
 	s.step = 0
+	s.err = nil
+	s.action = 0
+	s.done = nil
+	s.savepoint = ""
+	s.started = time.Time{}
+	s.startProgress = 0
+	atomic.StoreInt32(&s.interrupted, 0)
+}
+
+// SetPolicy sets the policy applied when a stepper fails.
+func (s *Steps) SetPolicy(policy ErrorPolicy) *Steps {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.policy = policy
+	return s
+}
+
+// Policy return the policy applied when a stepper fails.
+func (s *Steps) Policy() ErrorPolicy {
+	return s.policy
+}
+
+// run executes the steppers from start with action and handles failures by the policy.
+// An interruption is handled as a failure before the next stepper.
+// Only failures of non-optional steppers are returned.
+// The errors of steppers before the current one are kept in s.err.
+func (s *Steps) run(action int, start int) error {
+	if s.done == nil {
+		s.done = make([]bool, len(s.steppers))
+	}
+	s.ensureState()
+	s.action = action
+	s.step = start * action
+	s.started = time.Now()
+	s.startProgress = s.Progress()
+	var errs errorList
+	var prepared []int
+	if action > 0 {
+		var err error
+		if prepared, err = s.prepareAborted(start); err != nil {
+			errs = s.abort(errs.append(err), prepared)
+			s.err = errs.err()
+			return s.err
+		}
+	}
+	failed := false
+	for i := start; i < len(s.steppers); i++ {
+		ss := s.steppers[i]
+		s.err = errs.err()
+		if s.isInterrupted() {
+			errs = s.abort(errs.append(ErrStepsInterrupted), prepared)
+			prepared = nil
+			failed = true
+			s.err = errs.err()
+			if s.policy == RollbackOnError {
+				errs = s.rollback(errs, i, i)
+			}
+			break
+		}
+		s.step += action
+		var err error
+		prepared, err = s.apply(i, action, prepared)
+		if err == nil {
+			s.done[i] = true
+			continue
+		}
+		errs = errs.append(err)
+		if isOptional(ss) {
+			continue
+		}
+		failed = true
+		if s.policy == ContinueOnError {
+			continue
+		}
+		errs = s.abort(errs, prepared)
+		prepared = nil
+		if s.policy == RollbackOnError {
+			errs = s.rollback(errs, i, i+1)
+		}
+		break
+	}
+	if failed {
+		return s.abort(errs, prepared).err()
+	}
+	errs, i := s.commit(errs, prepared)
+	if i < 0 {
+		return nil
+	}
+	s.err = errs.err()
+	if s.policy == RollbackOnError {
+		errs = s.rollback(errs, i, len(s.steppers))
+	}
+	return errs.err()
+}
+
+// rollback reverts the steppers before to back to the last savepoint not after the
+// index-th stepper, and return errs with the errors of reverting.
+func (s *Steps) rollback(errs errorList, index int, to int) errorList {
+	name, from := s.lastSavepoint(index)
+	errs = errs.append(s.revert(from, to))
+	s.step = from * s.action
+	s.savepoint = name
+	s.err = errs.err()
+	return errs
 }
 
 func (s *Steps) checkSteppers() error {
@@ -0,0 +1,234 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// ResourceKind is the kind of tracked resource.
+type ResourceKind string
+
+const (
+	// ResourceFile is a file removed on release.
+	ResourceFile ResourceKind = "file"
+	// ResourceDir is a directory removed with its content on release.
+	ResourceDir ResourceKind = "dir"
+	// ResourceProcess is a process killed on release.
+	// A process restored from JSON is not killed, since its PID may be reused after restart.
+	ResourceProcess ResourceKind = "process"
+	// ResourceFunc is a custom resource released by its named cleanup.
+	ResourceFunc ResourceKind = "func"
+)
+
+// Resource is an artifact tracked for release.
+type Resource struct {
+	Kind ResourceKind `json:"kind"`
+	Path string       `json:"path,omitempty"`
+	PID  int          `json:"pid,omitempty"`
+	Name string       `json:"name,omitempty"`
+}
+
+// Tracker records the resources created by a doer, and releases them in reverse order.
+// It marshals into JSON with its resources, and cleanups of custom resources are bound by name.
+type Tracker struct {
+	mutex     *sync.Mutex
+	resources []Resource
+	cleanups  map[string]func() error
+	processes map[int]*os.Process
+}
+
+// NewTracker creates an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		mutex:     &sync.Mutex{},
+		cleanups:  map[string]func() error{},
+		processes: map[int]*os.Process{},
+	}
+}
+
+// File tracks the file at path.
+func (t *Tracker) File(path string) {
+	t.track(Resource{Kind: ResourceFile, Path: path})
+}
+
+// Dir tracks the directory at path.
+func (t *Tracker) Dir(path string) {
+	t.track(Resource{Kind: ResourceDir, Path: path})
+}
+
+// TempDir creates and tracks a temporary directory like ioutil.TempDir.
+func (t *Tracker) TempDir(dir, pattern string) (string, error) {
+	path, err := ioutil.TempDir(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	t.Dir(path)
+	return path, nil
+}
+
+// Process tracks the process.
+func (t *Tracker) Process(process *os.Process) {
+	t.mutex.Lock()
+	t.processes[process.Pid] = process
+	t.mutex.Unlock()
+	t.track(Resource{Kind: ResourceProcess, PID: process.Pid})
+}
+
+// Func tracks a custom resource released by cleanup.
+func (t *Tracker) Func(name string, cleanup func() error) {
+	t.Handle(name, cleanup)
+	t.track(Resource{Kind: ResourceFunc, Name: name})
+}
+
+// Handle binds cleanup to the custom resources of name, such as after unmarshaling.
+func (t *Tracker) Handle(name string, cleanup func() error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.cleanups[name] = cleanup
+}
+
+// Resources return the tracked resources in order.
+func (t *Tracker) Resources() []Resource {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]Resource{}, t.resources...)
+}
+
+// Release releases the tracked resources in reverse order.
+// Resources failed to release are kept for another try,
+// except the processes restored from JSON, which are dropped with ErrTrackerProcessUnknown.
+func (t *Tracker) Release() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var errs errorList
+	var kept []Resource
+	for i := len(t.resources) - 1; i >= 0; i-- {
+		if err := t.release(t.resources[i]); err != nil {
+			errs = errs.append(err)
+			if !errors.Is(err, ErrTrackerProcessUnknown) {
+				kept = append([]Resource{t.resources[i]}, kept...)
+			}
+		}
+	}
+	t.resources = kept
+	return errs.err()
+}
+
+// MarshalJSON marshals the tracked resources.
+func (t *Tracker) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Resources())
+}
+
+// UnmarshalJSON restores the tracked resources marshaled by MarshalJSON.
+func (t *Tracker) UnmarshalJSON(b []byte) error {
+	var resources []Resource
+	if err := json.Unmarshal(b, &resources); err != nil {
+		return err
+	}
+	if t.mutex == nil {
+		*t = *NewTracker()
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.resources = resources
+	return nil
+}
+
+func (t *Tracker) track(resource Resource) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.resources = append(t.resources, resource)
+}
+
+func (t *Tracker) release(resource Resource) error {
+	switch resource.Kind {
+	case ResourceFile:
+		return removeIfExist(resource.Path)
+	case ResourceDir:
+		return os.RemoveAll(resource.Path)
+	case ResourceProcess:
+		process := t.processes[resource.PID]
+		if process == nil {
+			return fmt.Errorf("%d: %w", resource.PID, ErrTrackerProcessUnknown)
+		}
+		if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+		delete(t.processes, resource.PID)
+		return nil
+	case ResourceFunc:
+		cleanup := t.cleanups[resource.Name]
+		if cleanup == nil {
+			return fmt.Errorf("%s: %w", resource.Name, ErrTrackerNoCleanup)
+		}
+		return call(cleanup)
+	}
+	return nil
+}
+
+// TrackedStep is the step giving its doer a tracker, and undoing by releasing the tracked resources.
+// The tracker is kept in the state of run under key, so that it is restored with the state.
+type TrackedStep struct {
+	*baseStep
+	key      string
+	doer     func(*Tracker) error
+	tracker  *Tracker
+	cleanups map[string]func() error
+}
+
+// NewTrackedStep creates step running doer with a tracker kept under key in the state of run.
+func NewTrackedStep(key string, doer func(*Tracker) error) *TrackedStep {
+	s := &TrackedStep{
+		key:      key,
+		doer:     doer,
+		cleanups: map[string]func() error{},
+	}
+	var do func() error
+	if doer != nil {
+		do = s.track
+	}
+	s.baseStep = NewStep(do, s.release)
+	return s
+}
+
+// Handle binds cleanup to the custom resources of name in a restored tracker.
+func (s *TrackedStep) Handle(name string, cleanup func() error) *TrackedStep {
+	s.cleanups[name] = cleanup
+	return s
+}
+
+// Tracker return the tracker of step, or nil if it is neither created nor restored.
+func (s *TrackedStep) Tracker() *Tracker {
+	if s.tracker == nil && s.state != nil {
+		if tracker, ok := Lookup[*Tracker](s.state, s.key); ok && tracker != nil {
+			for name, cleanup := range s.cleanups {
+				tracker.Handle(name, cleanup)
+			}
+			s.tracker = tracker
+		}
+	}
+	return s.tracker
+}
+
+func (s *TrackedStep) track() error {
+	s.tracker = NewTracker()
+	for name, cleanup := range s.cleanups {
+		s.tracker.Handle(name, cleanup)
+	}
+	if s.state != nil {
+		s.state.Set(s.key, s.tracker)
+	}
+	return s.doer(s.tracker)
+}
+
+func (s *TrackedStep) release() error {
+	tracker := s.Tracker()
+	if tracker == nil {
+		return ErrTrackerNotFound
+	}
+	return tracker.Release()
+}
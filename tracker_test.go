@@ -0,0 +1,144 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTrackedStep(t *testing.T) {
+	dir := t.TempDir()
+	var calls []string
+	var temp string
+	s := NewTrackedStep("tracker", func(tracker *Tracker) error {
+		os.Mkdir(filepath.Join(dir, "d"), 0755)
+		tracker.Dir(filepath.Join(dir, "d"))
+		os.WriteFile(filepath.Join(dir, "d", "f"), nil, 0644)
+		tracker.File(filepath.Join(dir, "d", "f"))
+		var err error
+		temp, err = tracker.TempDir(dir, "temp-")
+		tracker.Func("service", func() error {
+			calls = append(calls, "stop service")
+			return nil
+		})
+		return err
+	})
+	state := NewState()
+	s.SetState(state)
+
+	t.Log("Track the resources of doer.")
+	if err := s.Do(); err != nil {
+		t.Fatal("TrackedStep should be able to do.")
+	}
+	expected := []Resource{
+		{Kind: ResourceDir, Path: filepath.Join(dir, "d")},
+		{Kind: ResourceFile, Path: filepath.Join(dir, "d", "f")},
+		{Kind: ResourceDir, Path: temp},
+		{Kind: ResourceFunc, Name: "service"},
+	}
+	if !reflect.DeepEqual(s.Tracker().Resources(), expected) {
+		t.Errorf("Tracker should have %v, got %v.", expected, s.Tracker().Resources())
+	}
+
+	t.Log("Release the resources restored from state.")
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal("State should be able to marshal.")
+	}
+	restored := NewState()
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatal("State should be able to unmarshal.")
+	}
+	s = NewTrackedStep("tracker", nil).Handle("service", func() error {
+		calls = append(calls, "stop restored service")
+		return nil
+	})
+	s.SetState(restored)
+	if err := s.Undo(); err != nil {
+		t.Fatalf("TrackedStep should be able to undo, got %v.", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"stop restored service"}) {
+		t.Errorf("Custom cleanup should be bound by name, got %v.", calls)
+	}
+	for _, path := range []string{filepath.Join(dir, "d"), temp} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be released.", path)
+		}
+	}
+	if len(s.Tracker().Resources()) != 0 {
+		t.Error("Released resources should not be tracked.")
+	}
+
+	t.Log("Undo without tracker.")
+	if err := NewTrackedStep("tracker", nil).Undo(); err != ErrTrackerNotFound {
+		t.Errorf("TrackedStep should return %v, got %v.", ErrTrackerNotFound, err)
+	}
+}
+
+func TestTrackerRelease(t *testing.T) {
+	errCleanup := errors.New("cleanup")
+	tracker := NewTracker()
+	var calls []string
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		tracker.Func(name, func() error {
+			calls = append(calls, name)
+			if name == "b" {
+				return errCleanup
+			}
+			return nil
+		})
+	}
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err == nil {
+		tracker.Process(cmd.Process)
+	}
+
+	t.Log("Release resources in reverse order.")
+	if err := tracker.Release(); err != errCleanup {
+		t.Errorf("Tracker should return %v, got %v.", errCleanup, err)
+	}
+	if !reflect.DeepEqual(calls, []string{"c", "b", "a"}) {
+		t.Errorf("Tracker should release in reverse order, got %v.", calls)
+	}
+	if !reflect.DeepEqual(tracker.Resources(), []Resource{{Kind: ResourceFunc, Name: "b"}}) {
+		t.Errorf("Failed resource should be kept, got %v.", tracker.Resources())
+	}
+	if cmd.Process != nil && cmd.Wait() == nil {
+		t.Error("Process should be killed.")
+	}
+
+	t.Log("Release a restored resource without cleanup.")
+	restored := NewTracker()
+	b, _ := json.Marshal(tracker)
+	json.Unmarshal(b, restored)
+	if err := restored.Release(); !errors.Is(err, ErrTrackerNoCleanup) {
+		t.Errorf("Tracker should return %v, got %v.", ErrTrackerNoCleanup, err)
+	}
+
+	t.Log("Refuse to kill a restored process.")
+	cmd = exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skip("Process is not able to start.")
+	}
+	defer cmd.Process.Kill()
+	tracker = NewTracker()
+	tracker.Process(cmd.Process)
+	restored = NewTracker()
+	b, _ = json.Marshal(tracker)
+	json.Unmarshal(b, restored)
+	if err := restored.Release(); !errors.Is(err, ErrTrackerProcessUnknown) {
+		t.Errorf("Tracker should return %v, got %v.", ErrTrackerProcessUnknown, err)
+	}
+	if len(restored.Resources()) != 0 {
+		t.Error("Restored process should be dropped.")
+	}
+	cmd.Process.Signal(os.Interrupt)
+	if err := cmd.Wait(); err == nil || err.Error() != "signal: interrupt" {
+		t.Errorf("Restored process should not be killed, got %v.", err)
+	}
+}
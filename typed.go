@@ -0,0 +1,54 @@
+package installer
+
+// baseStep is embedded by the steps built on Step, so that the method Step is promoted.
+type baseStep = Step
+
+// TypedStep is the step whose doer returns a value, and whose undoer receives the value.
+type TypedStep[T any] struct {
+	*baseStep
+	value T
+	ok    bool
+}
+
+// NewTypedStep creates step with doer returning a value, and undoer receiving the value.
+func NewTypedStep[T any](doer func() (T, error), undoer func(T) error) *TypedStep[T] {
+	t := &TypedStep[T]{}
+	var do, undo func() error
+	if doer != nil {
+		do = func() error {
+			value, err := doer()
+			if err != nil {
+				return err
+			}
+			t.value = value
+			t.ok = true
+			return nil
+		}
+	}
+	if undoer != nil {
+		undo = func() error {
+			if !t.ok {
+				return ErrStepNoValue
+			}
+			return undoer(t.value)
+		}
+	}
+	t.baseStep = NewStep(do, undo)
+	return t
+}
+
+// Value return the value returned by doer or set by SetValue.
+// The value is kept by Reset, so that it can be undone afterward.
+func (t *TypedStep[T]) Value() (T, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.value, t.ok
+}
+
+// SetValue sets the value received by undoer, e.g. restored after restart.
+func (t *TypedStep[T]) SetValue(value T) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.value = value
+	t.ok = true
+}
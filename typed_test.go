@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypedStep(t *testing.T) {
+	t.Log("Undo with the value returned by doer.")
+	var undone int
+	s := NewTypedStep(
+		func() (int, error) { return 42, nil },
+		func(pid int) error {
+			undone = pid
+			return nil
+		},
+	)
+	if _, ok := s.Value(); ok {
+		t.Error("Value should not be able to get before do.")
+	}
+	if err := s.Do(); err != nil {
+		t.Error("Typed step should be able to do.")
+	}
+	if v, ok := s.Value(); !ok || v != 42 {
+		t.Error("Value should be able to get.")
+	}
+	s.Reset()
+	if err := s.Undo(); err != nil || undone != 42 {
+		t.Error("Undoer should receive the value.")
+	}
+
+	t.Log("Keep no value from a failed doer.")
+	f := NewTypedStep(
+		func() (string, error) { return "a", errors.New("") },
+		func(string) error { return nil },
+	)
+	if err := f.Do(); err == nil {
+		t.Error("Typed step should fail.")
+	}
+	f.Reset()
+	if err := f.Undo(); err != ErrStepNoValue {
+		t.Error("Typed step should not be able to undo without value.")
+	}
+
+	t.Log("Undo with a restored value.")
+	r := NewTypedStep(nil, func(pid int) error {
+		undone = pid
+		return nil
+	})
+	r.SetValue(7)
+	if err := r.Undo(); err != nil || undone != 7 {
+		t.Error("Undoer should receive the restored value.")
+	}
+
+	t.Log("Use a typed step as a stepper.")
+	var _ Stepper = r
+	if err := NewTypedStep[int](nil, nil).Do(); err != ErrStepNoDoer {
+		t.Error("Typed step should not be able to do.")
+	}
+}
@@ -0,0 +1,244 @@
+package installer
+
+import (
+	"sort"
+	"sync"
+)
+
+// Receipt is the record of an installed release.
+type Receipt struct {
+	Version string   `json:"version"`
+	Steps   []string `json:"steps"`
+}
+
+// Release is the definition of an installer version with named steppers and migrations.
+type Release struct {
+	mutex      *sync.Mutex
+	version    string
+	names      []string
+	steppers   map[string]Stepper
+	migrations map[string]map[string]Stepper
+}
+
+// NewRelease creates release of version.
+func NewRelease(version string) *Release {
+	return &Release{
+		mutex:      &sync.Mutex{},
+		version:    version,
+		steppers:   map[string]Stepper{},
+		migrations: map[string]map[string]Stepper{},
+	}
+}
+
+// Add appends a named stepper to release, or replaces the one with the same name.
+func (r *Release) Add(name string, stepper Stepper) *Release {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.steppers[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.steppers[name] = stepper
+	return r
+}
+
+// Migrate registers a stepper migrating data from a version to another.
+// A nil stepper registers that no migration is needed.
+func (r *Release) Migrate(from, to string, stepper Stepper) *Release {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.migrations[from] == nil {
+		r.migrations[from] = map[string]Stepper{}
+	}
+	r.migrations[from][to] = stepper
+	return r
+}
+
+// Version return the version of release.
+func (r *Release) Version() string {
+	return r.version
+}
+
+// Receipt return the receipt of release once installed.
+func (r *Release) Receipt() *Receipt {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return &Receipt{
+		Version: r.version,
+		Steps:   append([]string{}, r.names...),
+	}
+}
+
+// Install creates steps installing release from scratch.
+func (r *Release) Install() *Steps {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	steppers := make([]Stepper, len(r.names))
+	for i, name := range r.names {
+		steppers[i] = r.steppers[name]
+	}
+	return NewSteps(steppers)
+}
+
+// migration return the chain of migrations from a version to another.
+// The shortest chain is chosen, and false is returned if the versions are not connected.
+func (r *Release) migration(from, to string) ([]Stepper, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	type path struct {
+		version  string
+		steppers []Stepper
+	}
+	visited := map[string]bool{from: true}
+	queue := []path{{version: from}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p.version == to {
+			return p.steppers, true
+		}
+		nexts := make([]string, 0, len(r.migrations[p.version]))
+		for next := range r.migrations[p.version] {
+			nexts = append(nexts, next)
+		}
+		sort.Strings(nexts)
+		for _, next := range nexts {
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			steppers := append([]Stepper{}, p.steppers...)
+			if stepper := r.migrations[p.version][next]; stepper != nil {
+				steppers = append(steppers, stepper)
+			}
+			queue = append(queue, path{
+				version:  next,
+				steppers: steppers,
+			})
+		}
+	}
+	return nil, false
+}
+
+// Upgrade is the steps upgrading an installed release to the next one.
+// It adds the new steppers, replaces the kept ones, runs the migrations,
+// then removes the dropped steppers, and rolls back to the installed release on any failure.
+type Upgrade struct {
+	*Steps
+	installed *Receipt
+	next      *Receipt
+}
+
+// NewUpgrade creates upgrade from the installed receipt to the next release.
+// A chain of migrations registered in next release is required between different versions.
+// Between different versions, a stepper in both releases is replaced by undoing the previous one
+// and doing the next one, unless both releases share the same stepper.
+// The previous release provides the steppers to remove or replace,
+// and may be nil if nothing is removed or replaced.
+// A nil receipt means nothing is installed.
+func NewUpgrade(installed *Receipt, previous, next *Release) (*Upgrade, error) {
+	if next == nil {
+		return nil, ErrUpgradeNoRelease
+	}
+	if installed == nil {
+		installed = &Receipt{}
+	}
+	receipt := next.Receipt()
+	changed := installed.Version != "" && installed.Version != receipt.Version
+
+	kept := map[string]bool{}
+	for _, name := range installed.Steps {
+		kept[name] = true
+	}
+	var steppers []Stepper
+	for _, name := range receipt.Steps {
+		if !kept[name] {
+			steppers = append(steppers, next.steppers[name])
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if previous == nil || previous.steppers[name] == nil {
+			return nil, ErrUpgradeNoStepper
+		}
+		if previous.steppers[name] == next.steppers[name] {
+			continue
+		}
+		steppers = append(steppers, &inverse{
+			Stepper: previous.steppers[name],
+		}, next.steppers[name])
+	}
+	if changed {
+		migrations, ok := next.migration(installed.Version, receipt.Version)
+		if !ok {
+			return nil, ErrUpgradeNoMigration
+		}
+		steppers = append(steppers, migrations...)
+	}
+	wanted := map[string]bool{}
+	for _, name := range receipt.Steps {
+		wanted[name] = true
+	}
+	for i := len(installed.Steps) - 1; i >= 0; i-- {
+		name := installed.Steps[i]
+		if wanted[name] {
+			continue
+		}
+		if previous == nil || previous.steppers[name] == nil {
+			return nil, ErrUpgradeNoStepper
+		}
+		steppers = append(steppers, &inverse{
+			Stepper: previous.steppers[name],
+		})
+	}
+	if len(steppers) == 0 {
+		steppers = append(steppers, NewStep(
+			func() error { return nil },
+			func() error { return nil },
+		))
+	}
+
+	return &Upgrade{
+		Steps:     NewSteps(steppers).SetPolicy(RollbackOnError),
+		installed: installed,
+		next:      receipt,
+	}, nil
+}
+
+// Receipt return the receipt of next release if upgrade is done successfully,
+// otherwise the installed one.
+func (u *Upgrade) Receipt() *Receipt {
+	if u.Action() == 1 && u.Fin() && u.Error() == nil {
+		return u.next
+	}
+	return u.installed
+}
+
+// inverse swaps the doer and undoer of stepper to remove it.
+type inverse struct {
+	Stepper
+}
+
+func (i *inverse) Do() error {
+	return i.Stepper.Undo()
+}
+
+func (i *inverse) Undo() error {
+	return i.Stepper.Do()
+}
+
+func (i *inverse) Action() int {
+	return -i.Stepper.Action()
+}
+
+func (i *inverse) Weight() float64 {
+	return weightOf(i.Stepper)
+}
+
+func (i *inverse) Interrupt() {
+	interrupt([]Stepper{i.Stepper})
+}
+
+func (i *inverse) SetState(state *State) {
+	setState([]Stepper{i.Stepper}, state)
+}
@@ -0,0 +1,121 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	var calls []string
+	shared := recordStep(&calls, "shared", nil, nil)
+	previous := NewRelease("1.0").
+		Add("bin", recordStep(&calls, "bin 1.0", nil, nil)).
+		Add("shared", shared).
+		Add("legacy", recordStep(&calls, "legacy", nil, nil))
+	next := NewRelease("1.2").
+		Add("bin", recordStep(&calls, "bin 1.2", nil, nil)).
+		Add("shared", shared).
+		Add("docs", recordStep(&calls, "docs", nil, nil)).
+		Migrate("1.0", "1.1", recordStep(&calls, "1.0-1.1", nil, nil)).
+		Migrate("1.1", "1.2", recordStep(&calls, "1.1-1.2", nil, nil)).
+		Migrate("0.9", "1.2", recordStep(&calls, "0.9-1.2", nil, nil))
+
+	t.Log("Upgrade the installed release.")
+	u, err := NewUpgrade(previous.Receipt(), previous, next)
+	if err != nil {
+		t.Fatal("Upgrade should be able to create.")
+	}
+	if u.Receipt().Version != "1.0" {
+		t.Error("Receipt should be the installed one before upgrade.")
+	}
+	if err := u.Do(); err != nil {
+		t.Fatal("Upgrade should be able to do.")
+	}
+	expected := []string{"undo bin 1.0", "do bin 1.2", "do docs", "do 1.0-1.1", "do 1.1-1.2", "undo legacy"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Upgrade should apply %v, got %v.", expected, calls)
+	}
+	if !reflect.DeepEqual(u.Receipt(), next.Receipt()) {
+		t.Error("Receipt should be the next one after upgrade.")
+	}
+}
+
+func TestUpgradeReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	previous := NewRelease("1.0").
+		Add("config", NewFileStep(path, []byte("1.0"), 0644))
+	next := NewRelease("2.0").
+		Add("config", NewFileStep(path, []byte("2.0"), 0644)).
+		Migrate("1.0", "2.0", nil)
+	if err := NewFileStep(path, []byte("1.0"), 0644).Do(); err != nil {
+		t.Fatal("Previous release should be installed.")
+	}
+
+	t.Log("Replace a kept step whose content changed.")
+	u, err := NewUpgrade(previous.Receipt(), previous, next)
+	if err != nil {
+		t.Fatal("Upgrade should be able to create.")
+	}
+	if err := u.Do(); err != nil {
+		t.Fatalf("Upgrade should be able to do, got %v.", err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "2.0" {
+		t.Errorf("Kept step should be replaced, got %q.", b)
+	}
+
+	t.Log("Keep the steps of the same version.")
+	u, _ = NewUpgrade(next.Receipt(), next, next)
+	if len(u.steppers) != 1 {
+		t.Error("Steps of the same version should not be replaced.")
+	}
+}
+
+func TestUpgradeRollback(t *testing.T) {
+	errDo := errors.New("do")
+	var calls []string
+	previous := NewRelease("1.0").
+		Add("legacy", recordStep(&calls, "legacy", nil, nil))
+	next := NewRelease("2.0").
+		Add("bin", recordStep(&calls, "bin", nil, nil)).
+		Migrate("1.0", "2.0", recordStep(&calls, "migrate", errDo, nil))
+
+	t.Log("Roll back a failed upgrade.")
+	u, _ := NewUpgrade(previous.Receipt(), previous, next)
+	if err := u.Do(); err != errDo {
+		t.Fatal("Upgrade should fail.")
+	}
+	expected := []string{"do bin", "do migrate", "undo bin"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Upgrade should roll back %v, got %v.", expected, calls)
+	}
+	if u.Receipt().Version != "1.0" {
+		t.Error("Receipt should be the installed one after rollback.")
+	}
+}
+
+func TestUpgradeError(t *testing.T) {
+	var test = []struct {
+		installed *Receipt
+		previous  *Release
+		next      *Release
+		err       error
+	}{
+		{nil, nil, nil, ErrUpgradeNoRelease},
+		{&Receipt{Version: "1.0", Steps: []string{"legacy"}}, nil, NewRelease("2.0").Migrate("1.0", "2.0", nil), ErrUpgradeNoStepper},
+		{&Receipt{Version: "1.0", Steps: []string{"legacy"}}, NewRelease("1.0"), NewRelease("2.0").Migrate("1.0", "2.0", nil), ErrUpgradeNoStepper},
+		{&Receipt{Version: "1.0", Steps: []string{"bin"}}, nil, NewRelease("2.0").Add("bin", NewStep(nil, nil)).Migrate("1.0", "2.0", nil), ErrUpgradeNoStepper},
+		{&Receipt{Version: "1.0"}, nil, NewRelease("2.0"), ErrUpgradeNoMigration},
+		{&Receipt{Version: "1.0"}, nil, NewRelease("2.0").Migrate("0.9", "2.0", nil), ErrUpgradeNoMigration},
+		{&Receipt{Version: "1.0"}, nil, NewRelease("2.0").Migrate("1.0", "2.0", nil), nil},
+		{&Receipt{Version: "1.0"}, nil, NewRelease("1.0"), nil},
+		{nil, nil, NewRelease("1.0"), nil},
+	}
+	for _, tt := range test {
+		if _, err := NewUpgrade(tt.installed, tt.previous, tt.next); err != tt.err {
+			t.Errorf("Upgrade should return %v, got %v.", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"fmt"
+)
+
+// Verifier implements the check whether the effects of stepper are still in place.
+type Verifier interface {
+	Verify() error
+}
+
+// DriftError reports the stepper whose effects are not in place.
+type DriftError struct {
+	Stepper Stepper
+	Err     error
+}
+
+// Error return the message of drift.
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("Stepper drifted: %v", e.Err)
+}
+
+// Unwrap return the error of drift.
+func (e *DriftError) Unwrap() error {
+	return e.Err
+}
+
+// Verify checks the tree of stepper, and reports each drifted stepper as DriftError.
+// Steppers not implementing Verifier are not checked.
+func Verify(stepper Stepper) error {
+	v, ok := stepper.(Verifier)
+	if !ok {
+		return nil
+	}
+	var errs errorList
+	for _, err := range (errorList{}).append(v.Verify()) {
+		if _, ok := err.(*DriftError); !ok {
+			err = &DriftError{
+				Stepper: stepper,
+				Err:     err,
+			}
+		}
+		errs = errs.append(err)
+	}
+	return errs.err()
+}
+
+// Verify checks all steppers.
+func (s *Steps) Verify() error {
+	return verifyAll(s.steppers)
+}
+
+// Verify checks all steppers.
+func (p *Parallel) Verify() error {
+	return verifyAll(p.steppers)
+}
+
+// Verify checks the stepper if the predicate held on last Do,
+// or if the predicate holds without an earlier Do.
+func (c *Conditional) Verify() error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	ok := c.held
+	if !c.decided {
+		ok, _ = c.predicate()
+	}
+	if !ok {
+		return nil
+	}
+	return Verify(c.stepper)
+}
+
+// Verify checks the stepper.
+func (o *Optional) Verify() error {
+	return Verify(o.Stepper)
+}
+
+// Verify checks the tree.
+func (c *Chaos) Verify() error {
+	return Verify(c.Stepper)
+}
+
+func (f *fault) Verify() error {
+	return Verify(f.Stepper)
+}
+
+func verifyAll(steppers []Stepper) error {
+	var errs errorList
+	for _, ss := range steppers {
+		errs = errs.append(Verify(ss))
+	}
+	return errs.err()
+}
@@ -0,0 +1,62 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	a := NewFileStep(filepath.Join(dir, "a"), []byte("a"), 0644)
+	b := NewFileStep(filepath.Join(dir, "b"), []byte("b"), 0644)
+	c := NewDirStep(filepath.Join(dir, "c"), 0755)
+	s := NewSteps([]Stepper{
+		a,
+		NewStep(func() error { return nil }, nil),
+		NewParallel([]Stepper{
+			NewOptional(b),
+		}),
+		When(
+			func() (bool, string) { return true, "" },
+			NewSteps([]Stepper{c}),
+		),
+		When(
+			func() (bool, string) { return false, "" },
+			NewFileStep(filepath.Join(dir, "d"), nil, 0644),
+		),
+	})
+
+	t.Log("Verify an intact installation.")
+	if err := s.Do(); err != nil {
+		t.Fatal("Steps should be able to do.")
+	}
+	if err := Verify(s); err != nil {
+		t.Error("Installation should be intact.")
+	}
+
+	t.Log("Verify a drifted installation.")
+	os.WriteFile(filepath.Join(dir, "a"), []byte("changed"), 0644)
+	os.Remove(filepath.Join(dir, "b"))
+	os.Remove(filepath.Join(dir, "c"))
+	err := s.Verify()
+	errs, ok := err.(*MultiError)
+	if !ok || len(errs.Errors) != 3 {
+		t.Fatal("Each drift should be reported.")
+	}
+	var test = []struct {
+		stepper Stepper
+		err     error
+	}{
+		{a, ErrVerifyChanged},
+		{b, ErrVerifyMissing},
+		{c, ErrVerifyMissing},
+	}
+	for i, tt := range test {
+		var drift *DriftError
+		if !errors.As(errs.Errors[i], &drift) || drift.Stepper != tt.stepper || !errors.Is(drift, tt.err) {
+			t.Errorf("Drift should be reported with the stepper, got %v.", errs.Errors[i])
+		}
+	}
+}
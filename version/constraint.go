@@ -0,0 +1,185 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of version ranges.
+// Comparators separated by spaces or commas must all match,
+// and ranges separated by "||" match if any of them does.
+//
+// Supported operators are =, !=, >, >=, <, <=, ~ and ^.
+// Partial versions and wildcards cover the whole range, such as "1.2" for ">=1.2.0 <1.3.0",
+// "~1.2.3" is ">=1.2.3 <1.3.0", and "^1.2.3" is ">=1.2.3 <2.0.0".
+type Constraint struct {
+	raw    string
+	ranges [][]comparator
+}
+
+type comparator func(Version) bool
+
+// ParseConstraint parses constraint expression.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{
+		raw: s,
+	}
+	for _, r := range strings.Split(s, "||") {
+		fields := strings.FieldsFunc(r, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		var comparators []comparator
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			if strings.Trim(field, "=!<>~^") == "" && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			cs, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", s, ErrInvalidConstraint)
+			}
+			comparators = append(comparators, cs...)
+		}
+		if len(fields) == 0 && len(strings.TrimSpace(s)) != 0 {
+			return nil, fmt.Errorf("%q: %w", s, ErrInvalidConstraint)
+		}
+		c.ranges = append(c.ranges, comparators)
+	}
+	return c, nil
+}
+
+// MustParseConstraint parses constraint expression and panics on error.
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check return whether version satisfies constraint.
+func (c *Constraint) Check(v Version) bool {
+	for _, r := range c.ranges {
+		ok := true
+		for _, compare := range r {
+			if !compare(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String return the expression of constraint.
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// parseComparator parses a comparator into primitive ones.
+func parseComparator(s string) ([]comparator, error) {
+	op := s[:len(s)-len(strings.TrimLeft(s, "=!<>~^"))]
+	v, given, err := parse(s[len(op):], true)
+	if err != nil {
+		return nil, err
+	}
+	exact := given == 3
+	switch op {
+	case "", "=":
+		if exact {
+			return []comparator{equal(v)}, nil
+		}
+		return between(v, bump(v, given), given), nil
+	case "!=":
+		if exact {
+			return []comparator{not(equal(v))}, nil
+		}
+		r := between(v, bump(v, given), given)
+		return []comparator{func(o Version) bool {
+			return !r[0](o) || !r[1](o)
+		}}, nil
+	case ">":
+		if exact {
+			return []comparator{greater(v)}, nil
+		}
+		if given == 0 {
+			return []comparator{never}, nil
+		}
+		return []comparator{not(less(bump(v, given)))}, nil
+	case ">=":
+		return []comparator{not(less(v))}, nil
+	case "<":
+		return []comparator{less(v)}, nil
+	case "<=":
+		if exact {
+			return []comparator{not(greater(v))}, nil
+		}
+		if given == 0 {
+			return []comparator{always}, nil
+		}
+		return []comparator{less(bump(v, given))}, nil
+	case "~":
+		if given >= 2 {
+			return between(v, bump(v, 2), 2), nil
+		}
+		return between(v, bump(v, given), given), nil
+	case "^":
+		switch {
+		case given == 0:
+			return []comparator{always}, nil
+		case v.Major > 0 || given == 1:
+			return between(v, bump(v, 1), 1), nil
+		case v.Minor > 0 || given == 2:
+			return between(v, bump(v, 2), 2), nil
+		}
+		return between(v, bump(v, 3), 3), nil
+	}
+	return nil, ErrInvalidConstraint
+}
+
+// bump return the lowest version above all versions sharing the given parts with v.
+func bump(v Version, given int) Version {
+	switch given {
+	case 1:
+		return Version{Major: v.Major + 1}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// between return comparators of range [lower, upper), or any version if no part is given.
+func between(lower, upper Version, given int) []comparator {
+	if given == 0 {
+		return []comparator{always, always}
+	}
+	return []comparator{not(less(lower)), less(upper)}
+}
+
+func equal(v Version) comparator {
+	return func(o Version) bool { return Compare(o, v) == 0 }
+}
+
+func less(v Version) comparator {
+	return func(o Version) bool { return Compare(o, v) < 0 }
+}
+
+func greater(v Version) comparator {
+	return func(o Version) bool { return Compare(o, v) > 0 }
+}
+
+func not(c comparator) comparator {
+	return func(o Version) bool { return !c(o) }
+}
+
+func always(Version) bool {
+	return true
+}
+
+func never(Version) bool {
+	return false
+}
@@ -0,0 +1,62 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConstraint(t *testing.T) {
+	var test = []struct {
+		constraint string
+		matched    []string
+		unmatched  []string
+	}{
+		{">=1.2 <2", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{">= 1.2, < 2", []string{"1.2.0"}, []string{"2.0.0"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.0"}},
+		{"1.2.x", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"!=1.2", []string{"1.3.0", "1.1.9"}, []string{"1.2.5"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{">1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"<=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~1", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"<1 || >=2.1", []string{"0.9.0", "2.1.0"}, []string{"1.5.0", "2.0.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, nil},
+		{"", []string{"1.0.0"}, nil},
+	}
+	for _, tt := range test {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("Constraint %q should be able to parse.", tt.constraint)
+			continue
+		}
+		if c.String() != tt.constraint {
+			t.Error("Constraint should keep its expression.")
+		}
+		for _, v := range tt.matched {
+			if !c.Check(MustParse(v)) {
+				t.Errorf("%s should satisfy %q.", v, tt.constraint)
+			}
+		}
+		for _, v := range tt.unmatched {
+			if c.Check(MustParse(v)) {
+				t.Errorf("%s should not satisfy %q.", v, tt.constraint)
+			}
+		}
+	}
+}
+
+func TestConstraintError(t *testing.T) {
+	for _, s := range []string{"=>1.2", ">=a", "1.2 ||", "~1.x.3", ">="} {
+		if _, err := ParseConstraint(s); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("Constraint %q should be invalid, got %v.", s, err)
+		}
+	}
+}
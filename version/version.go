@@ -0,0 +1,163 @@
+// Package version parses semantic versions and checks them against constraints.
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalid means the version is not a semantic version.
+	ErrInvalid = errors.New("Version is invalid")
+	// ErrInvalidConstraint means the constraint cannot be parsed.
+	ErrInvalidConstraint = errors.New("Version constraint is invalid")
+)
+
+// Version is a semantic version.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      string
+}
+
+// Parse parses a semantic version with an optional "v" prefix.
+// Missing minor or patch are treated as 0.
+func Parse(s string) (Version, error) {
+	v, _, err := parse(s, false)
+	return v, err
+}
+
+// MustParse parses a semantic version and panics on error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String return the canonical form of version.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare return -1, 0 or 1 if version is lower than, equal to or higher than other.
+// Build metadata is ignored.
+func (v Version) Compare(other Version) int {
+	return Compare(v, other)
+}
+
+// Compare return -1, 0 or 1 if a is lower than, equal to or higher than b.
+// Build metadata is ignored.
+func Compare(a, b Version) int {
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		if c := compareIdentifier(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(a.Prerelease), len(b.Prerelease))
+}
+
+// parse parses version, and return the number of given parts of major, minor and patch.
+// Wildcards "x" and "*" end the given parts if allowed.
+func parse(s string, wildcard bool) (Version, int, error) {
+	var v Version
+	invalid := fmt.Errorf("%q: %w", s, ErrInvalid)
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		v.Build = s[i+1:]
+		s = s[:i]
+		if v.Build == "" {
+			return v, 0, invalid
+		}
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.Prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+		for _, id := range v.Prerelease {
+			if id == "" {
+				return v, 0, invalid
+			}
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, invalid
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	given := 0
+	for i, part := range parts {
+		if wildcard && (part == "x" || part == "X" || part == "*") {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, 0, invalid
+		}
+		*numbers[i] = n
+		given++
+	}
+	for _, part := range parts[given:] {
+		if part != "x" && part != "X" && part != "*" {
+			return v, 0, invalid
+		}
+	}
+	if given < 3 && len(v.Prerelease) > 0 && wildcard {
+		return v, 0, invalid
+	}
+	return v, given, nil
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier compares prerelease identifiers,
+// numerically if both are numeric, and numeric ones are lower than others.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInt(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,63 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var test = []struct {
+		s      string
+		string string
+		err    error
+	}{
+		{"1.2.3", "1.2.3", nil},
+		{"v1.2.3", "1.2.3", nil},
+		{"1.2", "1.2.0", nil},
+		{"1", "1.0.0", nil},
+		{"1.2.3-rc.1+build.5", "1.2.3-rc.1+build.5", nil},
+		{"", "", ErrInvalid},
+		{"1.2.3.4", "", ErrInvalid},
+		{"1.x", "", ErrInvalid},
+		{"1.2.3-", "", ErrInvalid},
+		{"1.2.3+", "", ErrInvalid},
+		{"1..3", "", ErrInvalid},
+	}
+	for _, tt := range test {
+		v, err := Parse(tt.s)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse %q should return %v, got %v.", tt.s, tt.err, err)
+			continue
+		}
+		if err == nil && v.String() != tt.string {
+			t.Errorf("Parse %q should be %q, got %q.", tt.s, tt.string, v.String())
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var test = []struct {
+		a string
+		b string
+		c int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.3.0", "1.2.9", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.0.0+a", "1.0.0+b", 0},
+	}
+	for _, tt := range test {
+		if c := MustParse(tt.a).Compare(MustParse(tt.b)); c != tt.c {
+			t.Errorf("Compare %s and %s should be %d, got %d.", tt.a, tt.b, tt.c, c)
+		}
+		if c := Compare(MustParse(tt.b), MustParse(tt.a)); c != -tt.c {
+			t.Errorf("Compare %s and %s should be %d, got %d.", tt.b, tt.a, -tt.c, c)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package installer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/silver886/installer/backup"
+)
+
+// ChangeKind is the kind of change to a watched path.
+type ChangeKind int
+
+const (
+	// Created means the path did not exist before.
+	Created ChangeKind = iota
+	// Removed means the path does not exist after.
+	Removed
+	// Changed means the content, mode or target of the path changed.
+	Changed
+)
+
+// Change is a change to a watched path.
+type Change struct {
+	Path string
+	Kind ChangeKind
+}
+
+// entry is the state of a path in snapshot.
+type entry struct {
+	mode    os.FileMode
+	content []byte
+	target  string
+	saved   string
+}
+
+func (e entry) equal(other entry) bool {
+	return e.mode == other.mode && e.target == other.target && bytes.Equal(e.content, other.content)
+}
+
+// WatchStep is the step running a doer while watching directories,
+// and undoing by restoring them to the state before the doer.
+type WatchStep struct {
+	*baseStep
+	dirs   []string
+	doer   func() error
+	before map[string]entry
+	change []Change
+	backup *backup.Store
+}
+
+// NewWatchStep creates step running doer, with undo generated from the changes in dirs.
+// Undo is only available after Do.
+func NewWatchStep(doer func() error, dirs ...string) *WatchStep {
+	w := &WatchStep{
+		dirs: dirs,
+		doer: doer,
+	}
+	var do func() error
+	if doer != nil {
+		do = w.watch
+	}
+	w.baseStep = NewStep(do, w.restore)
+	return w
+}
+
+// SetBackup sets the store keeping the removed and changed files, in place of memory.
+func (w *WatchStep) SetBackup(store *backup.Store) *WatchStep {
+	w.backup = store
+	return w
+}
+
+// Changes return the changes made by doer in path order.
+func (w *WatchStep) Changes() []Change {
+	return append([]Change{}, w.change...)
+}
+
+func (w *WatchStep) watch() error {
+	before, err := snapshot(w.dirs)
+	if err != nil {
+		return err
+	}
+	err = call(w.doer)
+	after, snapErr := snapshot(w.dirs)
+	if snapErr != nil {
+		return errorList{}.append(err).append(snapErr).err()
+	}
+
+	w.change = w.change[:0]
+	for path, a := range after {
+		if b, ok := before[path]; !ok {
+			w.change = append(w.change, Change{Path: path, Kind: Created})
+		} else if !a.equal(b) {
+			w.change = append(w.change, Change{Path: path, Kind: Changed})
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			w.change = append(w.change, Change{Path: path, Kind: Removed})
+		}
+	}
+	sort.Slice(w.change, func(i, j int) bool {
+		return w.change[i].Path < w.change[j].Path
+	})
+
+	// Only the content to restore is kept.
+	w.before = map[string]entry{}
+	var errs errorList
+	for _, c := range w.change {
+		if c.Kind == Created {
+			continue
+		}
+		e := before[c.Path]
+		if w.backup != nil && e.mode.IsRegular() {
+			saved, err := w.backup.Put(c.Path, e.mode, e.content)
+			if err != nil {
+				errs = errs.append(err)
+			} else {
+				e.content = nil
+				e.saved = saved.ID
+			}
+		}
+		w.before[c.Path] = e
+	}
+	return errs.append(err).err()
+}
+
+func (w *WatchStep) restore() error {
+	if w.before == nil {
+		return ErrWatchNoSnapshot
+	}
+	var errs errorList
+	for i := len(w.change) - 1; i >= 0; i-- {
+		if w.change[i].Kind == Created {
+			errs = errs.append(os.RemoveAll(w.change[i].Path))
+		}
+	}
+	for _, c := range w.change {
+		if c.Kind != Created {
+			errs = errs.append(restoreEntry(c.Path, w.before[c.Path], w.backup))
+		}
+	}
+	return errs.err()
+}
+
+// snapshot records the state of every path under dirs, without following symbolic links.
+// Missing dirs are recorded as empty.
+func snapshot(dirs []string) (map[string]entry, error) {
+	entries := map[string]entry{}
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			e := entry{
+				mode: info.Mode(),
+			}
+			switch {
+			case info.Mode()&os.ModeSymlink != 0:
+				e.target, err = os.Readlink(path)
+			case info.Mode().IsRegular():
+				e.content, err = os.ReadFile(path)
+			}
+			entries[path] = e
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// restoreEntry restores path to the state in snapshot, reading the file content from store if saved.
+func restoreEntry(path string, e entry, store *backup.Store) error {
+	info, err := os.Lstat(path)
+	exists := err == nil
+	if exists && (info.Mode().Type() != e.mode.Type() || e.mode&os.ModeSymlink != 0) {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		exists = false
+	}
+	switch {
+	case e.mode.IsDir():
+		if !exists {
+			if err := os.Mkdir(path, e.mode.Perm()); err != nil {
+				return err
+			}
+		}
+	case e.mode&os.ModeSymlink != 0:
+		return os.Symlink(e.target, path)
+	case e.saved != "":
+		return store.Restore(e.saved)
+	default:
+		if err := os.WriteFile(path, e.content, e.mode.Perm()); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(path, e.mode.Perm())
+}
@@ -0,0 +1,105 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/silver886/installer/backup"
+)
+
+func TestWatchStep(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(dir, "b"), []byte("b"), 0600)
+	os.MkdirAll(filepath.Join(dir, "sub", "deep"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "deep", "c"), []byte("c"), 0644)
+	os.Symlink("a", filepath.Join(dir, "link"))
+	before, err := snapshot([]string{dir})
+	if err != nil {
+		t.Fatal("Snapshot should be able to take.")
+	}
+
+	w := NewWatchStep(func() error {
+		os.WriteFile(filepath.Join(dir, "a"), []byte("changed"), 0644)
+		os.Chmod(filepath.Join(dir, "b"), 0644)
+		os.RemoveAll(filepath.Join(dir, "sub"))
+		os.MkdirAll(filepath.Join(dir, "new", "dir"), 0755)
+		os.WriteFile(filepath.Join(dir, "new", "dir", "d"), []byte("d"), 0644)
+		os.Remove(filepath.Join(dir, "link"))
+		os.Symlink("b", filepath.Join(dir, "link"))
+		return nil
+	}, dir, filepath.Join(dir, "missing"))
+
+	t.Log("Watch the changes of doer.")
+	if err := w.Undo(); err != ErrWatchNoSnapshot {
+		t.Errorf("WatchStep should return %v before doing, got %v.", ErrWatchNoSnapshot, err)
+	}
+	w.Reset()
+	if err := w.Do(); err != nil {
+		t.Fatal("WatchStep should be able to do.")
+	}
+	expected := []Change{
+		{filepath.Join(dir, "a"), Changed},
+		{filepath.Join(dir, "b"), Changed},
+		{filepath.Join(dir, "link"), Changed},
+		{filepath.Join(dir, "new"), Created},
+		{filepath.Join(dir, "new", "dir"), Created},
+		{filepath.Join(dir, "new", "dir", "d"), Created},
+		{filepath.Join(dir, "sub"), Removed},
+		{filepath.Join(dir, "sub", "deep"), Removed},
+		{filepath.Join(dir, "sub", "deep", "c"), Removed},
+	}
+	if !reflect.DeepEqual(w.Changes(), expected) {
+		t.Errorf("WatchStep should record %v, got %v.", expected, w.Changes())
+	}
+
+	t.Log("Restore the state before doer.")
+	w.Reset()
+	if err := w.Undo(); err != nil {
+		t.Fatalf("WatchStep should be able to undo, got %v.", err)
+	}
+	after, _ := snapshot([]string{dir})
+	if len(after) != len(before) {
+		t.Fatalf("Snapshot should have %d paths, got %d.", len(before), len(after))
+	}
+	for path, e := range before {
+		if !e.equal(after[path]) {
+			t.Errorf("%s should be restored.", path)
+		}
+	}
+}
+
+func TestWatchStepBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watched", "config")
+	os.Mkdir(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("user"), 0600)
+	store := backup.NewStore(filepath.Join(dir, "backup")).SetCompress(true)
+
+	t.Log("Keep changed files in backup.")
+	w := NewWatchStep(func() error {
+		return os.WriteFile(path, []byte("vendor"), 0644)
+	}, filepath.Dir(path)).SetBackup(store)
+	if err := w.Do(); err != nil {
+		t.Fatal("WatchStep should be able to do.")
+	}
+	if entries, _ := store.List(); len(entries) != 1 || entries[0].Path != path {
+		t.Error("Changed file should be backed up.")
+	}
+	if w.before[path].content != nil {
+		t.Error("Backed up content should not be kept in memory.")
+	}
+
+	t.Log("Restore changed files from backup.")
+	w.Reset()
+	if err := w.Undo(); err != nil {
+		t.Fatalf("WatchStep should be able to undo, got %v.", err)
+	}
+	b, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+	if string(b) != "user" || info.Mode().Perm() != 0600 {
+		t.Error("Changed file should be restored with its mode.")
+	}
+}